@@ -0,0 +1,33 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Keys of the attributes made by Err, Dur and Component, which are
+// the same in records of every logger
+const (
+	ErrorKey     = "error"
+	DurationKey  = "duration"
+	ComponentKey = "component"
+)
+
+// Err returns an attribute holding an error
+func Err(err error) slog.Attr {
+	return slog.Any(ErrorKey, err)
+}
+
+// Dur returns an attribute holding the duration of an operation
+func Dur(d time.Duration) slog.Attr {
+	return slog.Duration(DurationKey, d)
+}
+
+// Component returns an attribute naming the part of a program emitting a record
+func Component(name string) slog.Attr {
+	return slog.String(ComponentKey, name)
+}
@@ -0,0 +1,54 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestAttrs(t *testing.T) {
+	tests := []struct {
+		name     string
+		attr     slog.Attr
+		wantText string
+		wantJSON string
+	}{
+		{
+			name:     "err",
+			attr:     Err(errors.New("no such file")),
+			wantText: `error="no such file"`,
+			wantJSON: `"error":"no such file"`,
+		},
+		{
+			name:     "dur",
+			attr:     Dur(1500 * time.Millisecond),
+			wantText: `duration=1.5s`,
+			wantJSON: `"duration":1500000000`,
+		},
+		{
+			name:     "component",
+			attr:     Component("db"),
+			wantText: `component=db`,
+			wantJSON: `"component":"db"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var text, json bytes.Buffer
+			slog.New(slog.NewTextHandler(&text, nil)).Info("msg", tt.attr)
+			slog.New(slog.NewJSONHandler(&json, nil)).Info("msg", tt.attr)
+			if !bytes.Contains(text.Bytes(), []byte(tt.wantText)) {
+				t.Errorf("%s() text got %s want %s", tt.name, text.String(), tt.wantText)
+			}
+			if !bytes.Contains(json.Bytes(), []byte(tt.wantJSON)) {
+				t.Errorf("%s() JSON got %s want %s", tt.name, json.String(), tt.wantJSON)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// genesis is the previous hash of the first record in an audit log
+	genesis = "0000000000000000000000000000000000000000000000000000000000000000"
+	// hashPrefix introduces the hash of an audit record at the end of its line
+	hashPrefix = `,"hash":"`
+)
+
+// auditChain is the state of the audit logger. Each record carries its
+// sequence number and the hash of the previous record, and ends with its own
+// hash so that any deletion or edit breaks the chain
+type auditChain struct {
+	lock        sync.Mutex
+	destination io.Writer
+	seq         uint64
+	prev        string
+}
+
+// auditLog has no destination until one is configured, so that audit records
+// are never interleaved with the records of other loggers
+var auditLog = auditChain{
+	prev: genesis,
+}
+
+// errNoAuditDestination is reported for audit records emitted before the
+// audit logger has a destination
+var errNoAuditDestination = errors.New("the audit logger has no destination")
+
+// emit writes one record to the audit log
+func (a *auditChain) emit(l slog.Level, msg string, pc uintptr, args ...any) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.destination == nil {
+		countWrite(Audit.String(), l, errNoAuditDestination)
+		reportError(Audit, errNoAuditDestination)
+		return
+	}
+	r := slog.NewRecord(time.Now(), l, msg, pc)
+	r.Add(args...)
+	r.AddAttrs(
+		slog.Uint64("seq", a.seq+1),
+		slog.String("prev", a.prev),
+	)
+	var body bytes.Buffer
+	h := slog.NewJSONHandler(
+		&body,
+		&slog.HandlerOptions{
+			Level: LevelTrace,
+			ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+				return levelAttr(a)
+			},
+		},
+	)
+	if err := h.Handle(context.Background(), r); err != nil {
+		countWrite(Audit.String(), l, err)
+		reportError(Audit, err)
+		return
+	}
+	record := bytes.TrimSuffix(body.Bytes(), []byte("\n"))
+	sum := sha256.Sum256(record)
+	hash := hex.EncodeToString(sum[:])
+	line := make([]byte, 0, len(record)+len(hashPrefix)+len(hash)+3)
+	line = append(line, record[:len(record)-1]...)
+	line = append(line, hashPrefix...)
+	line = append(line, hash...)
+	line = append(line, "\"}\n"...)
+	_, err := a.destination.Write(line)
+	countWrite(Audit.String(), l, err)
+	if err != nil {
+		reportError(Audit, err)
+		return
+	}
+	a.seq++
+	a.prev = hash
+}
+
+// auditDestination changes the audit logger's writer, starting a new chain
+func auditDestination(w io.Writer) {
+	auditLog.lock.Lock()
+	defer auditLog.lock.Unlock()
+	auditLog.destination = w
+	auditLog.seq = 0
+	auditLog.prev = genesis
+}
+
+// ResumeAudit makes w the audit logger's destination, continuing the chain of
+// the existing audit log read from r rather than starting a new one. The
+// existing log must pass VerifyAuditLog, otherwise the destination is not
+// changed. A file opened with os.O_RDWR|os.O_APPEND can be both r and w
+func ResumeAudit(w io.Writer, r io.Reader) error {
+	seq, prev, err := verifyAudit(r)
+	if err != nil {
+		return err
+	}
+	auditLog.lock.Lock()
+	defer auditLog.lock.Unlock()
+	auditLog.destination = w
+	auditLog.seq = seq
+	auditLog.prev = prev
+	return nil
+}
+
+// AuditRecord emits one record to the audit log. Audit records are always
+// JSON and are never filtered by level. Records are dropped until the audit
+// log has been given a destination by Configure or ResumeAudit
+func AuditRecord(msg string, args ...any) {
+	auditLog.emit(slog.LevelInfo, msg, callerPC(1), args...)
+}
+
+// VerifyAuditLog reads an audit log from its first record and checks that
+// no record has been edited, deleted, inserted or reordered. Records removed
+// from the end of the log cannot be detected
+func VerifyAuditLog(r io.Reader) error {
+	_, _, err := verifyAudit(r)
+	return err
+}
+
+// verifyAudit checks an audit log and returns the sequence number and hash of
+// its last record
+func verifyAudit(r io.Reader) (seq uint64, prev string, err error) {
+	br := bufio.NewReader(r)
+	prev = genesis
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) == 0 && errors.Is(err, io.EOF) {
+			return seq, prev, nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, "", fmt.Errorf("cannot read audit record %d: %w", seq+1, err)
+		}
+		seq++
+		line = bytes.TrimSuffix(line, []byte("\n"))
+		i := bytes.LastIndex(line, []byte(hashPrefix))
+		if i < 0 || !bytes.HasSuffix(line, []byte("\"}")) {
+			return 0, "", fmt.Errorf("audit record %d has no hash", seq)
+		}
+		hash := string(line[i+len(hashPrefix) : len(line)-2])
+		record := append(line[:i:i], '}')
+		sum := sha256.Sum256(record)
+		if hash != hex.EncodeToString(sum[:]) {
+			return 0, "", fmt.Errorf("audit record %d has been altered", seq)
+		}
+		var chain struct {
+			Seq  uint64 `json:"seq"`
+			Prev string `json:"prev"`
+		}
+		if err := json.Unmarshal(record, &chain); err != nil {
+			return 0, "", fmt.Errorf("audit record %d is invalid: %w", seq, err)
+		}
+		if chain.Seq != seq || chain.Prev != prev {
+			return 0, "", fmt.Errorf("audit record %d is out of sequence, found record %d", seq, chain.Seq)
+		}
+		prev = hash
+	}
+}
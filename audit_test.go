@@ -0,0 +1,194 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// auditLines writes records to a fresh audit log and returns its lines
+func auditLines(t *testing.T, n int) []string {
+	t.Helper()
+	w := &bytes.Buffer{}
+	auditDestination(w)
+	for i := range n {
+		AuditRecord("event", "i", i)
+	}
+	return strings.SplitAfter(strings.TrimSuffix(w.String(), "\n"), "\n")
+}
+
+func TestAuditRecord(t *testing.T) {
+	defer auditDestination(nil)
+	saveLevel := level.Level()
+	defer level.Set(saveLevel)
+	SetLevel(slog.LevelError)
+	lines := auditLines(t, 2)
+	wantRe := []string{
+		`^{"time":".+","level":"INFO","msg":"event","i":0,"seq":1,"prev":"0{64}","hash":"[0-9a-f]{64}"}\n$`,
+		`^{"time":".+","level":"INFO","msg":"event","i":1,"seq":2,"prev":"[0-9a-f]{64}","hash":"[0-9a-f]{64}"}$`,
+	}
+	if len(lines) != len(wantRe) {
+		t.Fatalf("AuditRecord() got %d records want %d", len(lines), len(wantRe))
+	}
+	for i := range lines {
+		ok, err := regexp.MatchString(wantRe[i], lines[i])
+		if !ok {
+			t.Errorf("AuditRecord() got %s want %s error %v", lines[i], wantRe[i], err)
+		}
+	}
+	hash := regexp.MustCompile(`"hash":"([0-9a-f]{64})"`).FindStringSubmatch(lines[0])[1]
+	if !strings.Contains(lines[1], `"prev":"`+hash+`"`) {
+		t.Errorf("AuditRecord() record 2 does not chain to hash %s of record 1", hash)
+	}
+}
+
+func TestVerifyAuditLog(t *testing.T) {
+	defer auditDestination(nil)
+	lines := auditLines(t, 4)
+	tests := []struct {
+		name    string
+		log     func() string
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			log: func() string {
+				return strings.Join(lines, "")
+			},
+			wantErr: false,
+		},
+		{
+			name: "empty",
+			log: func() string {
+				return ""
+			},
+			wantErr: false,
+		},
+		{
+			name: "edited",
+			log: func() string {
+				return strings.Replace(strings.Join(lines, ""), `"i":2`, `"i":7`, 1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "deleted",
+			log: func() string {
+				return lines[0] + lines[2] + lines[3]
+			},
+			wantErr: true,
+		},
+		{
+			name: "first-deleted",
+			log: func() string {
+				return lines[1] + lines[2] + lines[3]
+			},
+			wantErr: true,
+		},
+		{
+			name: "reordered",
+			log: func() string {
+				return lines[0] + lines[2] + lines[1] + lines[3]
+			},
+			wantErr: true,
+		},
+		{
+			name: "no-hash",
+			log: func() string {
+				return `{"msg":"event","seq":1}` + "\n"
+			},
+			wantErr: true,
+		},
+		{
+			name: "not-json",
+			log: func() string {
+				return `{"msg":"event"` + hashPrefix + `c8e6d7be3e1dbcfc0c5d7cf2ba3cbf0f2c5f1b1da5b4a1ab36aa1e8fc4ae5a1b"}` + "\n"
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyAuditLog(strings.NewReader(tt.log())); (err != nil) != tt.wantErr {
+				t.Errorf("VerifyAuditLog() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigure_audit(t *testing.T) {
+	defer auditDestination(nil)
+	w := &bytes.Buffer{}
+	if err := Configure(ConfigSetting{AppliesTo: Audit, Key: DestinationSetting, Value: w}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	LogTo(Audit, slog.LevelDebug, "event")
+	if w.Len() == 0 {
+		t.Errorf("Configure() audit log not written to destination")
+	}
+	if err := VerifyAuditLog(w); err != nil {
+		t.Errorf("VerifyAuditLog() error = %v", err)
+	}
+	if err := Configure(ConfigSetting{AppliesTo: Audit, Key: FormatSetting, Value: Text}); err == nil {
+		t.Errorf("Configure() audit format was changed")
+	}
+}
+
+func TestResumeAudit(t *testing.T) {
+	defer auditDestination(nil)
+	tests := []struct {
+		name    string
+		log     func([]string) string
+		wantErr bool
+	}{
+		{
+			name: "resume",
+			log:  func(lines []string) string { return strings.Join(lines, "") },
+		},
+		{
+			name: "empty",
+			log:  func([]string) string { return "" },
+		},
+		{
+			name:    "altered",
+			log:     func(lines []string) string { return strings.Replace(strings.Join(lines, ""), `"i":1`, `"i":9`, 1) },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := auditLines(t, 2)
+			lines[len(lines)-1] += "\n"
+			existing := tt.log(lines)
+			w := bytes.NewBufferString(existing)
+			if err := ResumeAudit(w, strings.NewReader(existing)); (err != nil) != tt.wantErr {
+				t.Fatalf("ResumeAudit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			AuditRecord("resumed")
+			if err := VerifyAuditLog(w); err != nil {
+				t.Errorf("VerifyAuditLog() after ResumeAudit() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestAuditRecord_noDestination(t *testing.T) {
+	saveHandler := SetErrorHandler(nil)
+	defer SetErrorHandler(saveHandler)
+	auditDestination(nil)
+	var got error
+	SetErrorHandler(func(_ LogID, err error) { got = err })
+	AuditRecord("dropped")
+	if got != errNoAuditDestination {
+		t.Errorf("AuditRecord() got error %v want %v", got, errNoAuditDestination)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// stdLogLevel is the level of records written by the standard library's
+// default logger, once redirected by RedirectStdLog
+var stdLogLevel atomic.Pointer[slog.Level]
+
+// stdWriter is the output of a log.Logger which writes to the normal logger
+type stdWriter struct {
+	level slog.Level
+}
+
+// Write emits one record for each output of a log.Logger, attributed to the
+// caller outside package log
+func (w stdWriter) Write(p []byte) (int, error) {
+	h := Handler(Norm)
+	ctx := context.Background()
+	if !h.Enabled(ctx, w.level) {
+		return len(p), nil
+	}
+	var pcs [8]uintptr
+	n := runtime.Callers(2, pcs[:]) // skip [Callers, Write]
+	pc := pcs[0]
+	for i := range n {
+		frame, _ := runtime.CallersFrames(pcs[i : i+1]).Next()
+		if !strings.HasPrefix(frame.Function, "log.") {
+			pc = pcs[i]
+			break
+		}
+	}
+	r := slog.NewRecord(time.Now(), w.level, string(bytes.TrimSuffix(p, []byte("\n"))), pc)
+	return len(p), h.Handle(ctx, r)
+}
+
+// StdLogger returns a log.Logger which writes to the normal logger at a
+// level, for packages such as net/http which accept one
+func StdLogger(l slog.Level) *log.Logger {
+	return log.New(stdWriter{level: l}, "", 0)
+}
+
+// RedirectStdLog sends the output of the standard library's default logger,
+// as used by log.Printf, to the normal logger at a level. The redirection
+// persists when the normal logger is changed by Configure
+func RedirectStdLog(l slog.Level) {
+	stdLogLevel.Store(&l)
+	redirectStdLog()
+}
+
+// redirectStdLog points the standard library's default logger at the normal
+// logger if RedirectStdLog has been called
+func redirectStdLog() {
+	if l := stdLogLevel.Load(); l != nil {
+		log.SetOutput(stdWriter{level: *l})
+		log.SetFlags(0)
+	}
+}
+
+// handlerOp is attributes or a group added to a Handler
+type handlerOp struct {
+	attrs []slog.Attr
+	group string
+}
+
+// logHandler writes to one of the loggers of this package as it is
+// currently configured
+type logHandler struct {
+	id  LogID
+	ops []handlerOp
+}
+
+// Handler returns a slog.Handler which writes to the identified logger. It
+// follows later changes made by Configure, SetLevel and SetLevelOverrides, and
+// the trace logger's handler only handles records when tracing is enabled.
+// Handler lets packages which build their own records, such as adapters for
+// other logging libraries, write to the loggers of this package
+func Handler(id LogID) slog.Handler {
+	return &logHandler{id: id}
+}
+
+// target returns the current handler and name of the logger
+func (h *logHandler) target() (slog.Handler, string, bool) {
+	switch h.id {
+	case Norm:
+		return slog.Default().Handler(), "", true
+	case Tracy:
+		return config.traceLogger.Handler(), "", true
+	}
+	n, ok := lookup(h.id)
+	if !ok {
+		return nil, "", false
+	}
+	return n.handler(), n.name, true
+}
+
+// Enabled reports whether the logger handles records at a level. When there
+// are level overrides, which depend upon the source of a record, Enabled is
+// true and Handle decides
+func (h *logHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	switch h.id {
+	case Tracy:
+		return tracing(l)
+	case Audit:
+		return true
+	}
+	if overrides.Load() != nil {
+		return true
+	}
+	t, _, ok := h.target()
+	return ok && t.Enabled(ctx, l)
+}
+
+// Handle writes a record to the logger if its level is enabled
+func (h *logHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.id == Audit {
+		auditLog.emit(r.Level, r.Message, r.PC, h.auditArgs(r)...)
+		return nil
+	}
+	t, name, ok := h.target()
+	if !ok {
+		return nil
+	}
+	if h.id == Tracy {
+		if !tracing(r.Level) {
+			return nil
+		}
+	} else if rules := overrides.Load(); rules != nil {
+		if ctx, ok = permitted(ctx, rules, t, name, r.Level, r.PC); !ok {
+			return nil
+		}
+	} else if !t.Enabled(ctx, r.Level) {
+		return nil
+	}
+	for _, op := range h.ops {
+		if op.group != "" {
+			t = t.WithGroup(op.group)
+		} else {
+			t = t.WithAttrs(op.attrs)
+		}
+	}
+	return t.Handle(ctx, r)
+}
+
+// auditArgs returns the attributes of an audit record, nested within the
+// groups of the handler
+func (h *logHandler) auditArgs(r slog.Record) []any {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	for i := len(h.ops) - 1; i >= 0; i-- {
+		if op := h.ops[i]; op.group != "" {
+			attrs = []slog.Attr{{Key: op.group, Value: slog.GroupValue(attrs...)}}
+		} else {
+			attrs = append(op.attrs[:len(op.attrs):len(op.attrs)], attrs...)
+		}
+	}
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	return args
+}
+
+// WithAttrs returns a handler which adds attributes to each record
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return &logHandler{id: h.id, ops: append(h.ops[:len(h.ops):len(h.ops)], handlerOp{attrs: attrs})}
+}
+
+// WithGroup returns a handler which adds a group to each record
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &logHandler{id: h.id, ops: append(h.ops[:len(h.ops):len(h.ops)], handlerOp{group: name})}
+}
@@ -0,0 +1,207 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"os"
+	"regexp"
+	"testing"
+
+	set "github.com/deckarep/golang-set/v2"
+)
+
+func TestStdLogger(t *testing.T) {
+	save := config
+	saveDefault := slog.Default()
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		slog.SetDefault(saveDefault)
+		level.Set(saveLevel)
+	}()
+	tests := []struct {
+		name   string
+		level  slog.Level
+		wantRe string
+	}{
+		{
+			name:   "warn",
+			level:  slog.LevelWarn,
+			wantRe: `^level=WARN source=bridge_test.go:\d+ msg="listener closed: eof"` + "\n$",
+		},
+		{
+			name:   "below-level",
+			level:  slog.LevelDebug,
+			wantRe: "^$",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			SetLevel(slog.LevelInfo)
+			_ = Configure(
+				ConfigSetting{AppliesTo: Norm, Key: DestinationSetting, Value: w},
+				ConfigSetting{AppliesTo: Norm, Key: OmitTimeSetting, Value: true},
+				ConfigSetting{AppliesTo: Norm, Key: SourceSetting, Value: Source{Text: true, Paths: ShortPaths}},
+			)
+			StdLogger(tt.level).Printf("listener closed: %s", "eof")
+			ok, err := regexp.MatchString(tt.wantRe, w.String())
+			if !ok {
+				t.Errorf("StdLogger() got %s want %s error %v", w.String(), tt.wantRe, err)
+			}
+		})
+	}
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	save := config
+	saveDefault := slog.Default()
+	saveLevel := level.Level()
+	saveFlags := log.Flags()
+	defer func() {
+		config = save
+		stdLogLevel.Store(nil)
+		slog.SetDefault(saveDefault)
+		level.Set(saveLevel)
+		log.SetOutput(os.Stderr)
+		log.SetFlags(saveFlags)
+	}()
+	SetLevel(slog.LevelInfo)
+	first := &bytes.Buffer{}
+	_ = Configure(
+		ConfigSetting{AppliesTo: Norm, Key: DestinationSetting, Value: first},
+		ConfigSetting{AppliesTo: Norm, Key: OmitTimeSetting, Value: true},
+	)
+	RedirectStdLog(slog.LevelError)
+	log.Printf("first %d", 1)
+	second := &bytes.Buffer{}
+	_ = Configure(ConfigSetting{AppliesTo: Norm, Key: DestinationSetting, Value: second})
+	log.Print("second")
+	if want := "level=ERROR msg=\"first 1\"\n"; first.String() != want {
+		t.Errorf("RedirectStdLog() got %s want %s", first.String(), want)
+	}
+	if want := "level=ERROR msg=second\n"; second.String() != want {
+		t.Errorf("RedirectStdLog() after Configure got %s want %s", second.String(), want)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	save := config
+	saveDefault := slog.Default()
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		slog.SetDefault(saveDefault)
+		level.Set(saveLevel)
+		_ = SetLevelOverrides("")
+		auditDestination(nil)
+		resetNamed()
+	}()
+	var (
+		normal = &bytes.Buffer{}
+		trace  = &bytes.Buffer{}
+		audit  = &bytes.Buffer{}
+		other  = &bytes.Buffer{}
+	)
+	id, err := RegisterLogger("other", Sink{Destination: other, Format: JSON, Level: slog.LevelWarn, OmitTime: true})
+	if err != nil {
+		t.Fatalf("RegisterLogger() error = %v", err)
+	}
+	_ = Configure(
+		ConfigSetting{AppliesTo: Norm, Key: DestinationSetting, Value: normal},
+		ConfigSetting{AppliesTo: Norm, Key: FormatSetting, Value: JSON},
+		ConfigSetting{AppliesTo: Norm, Key: OmitTimeSetting, Value: true},
+		ConfigSetting{AppliesTo: Tracy, Key: DestinationSetting, Value: trace},
+		ConfigSetting{AppliesTo: Tracy, Key: FormatSetting, Value: JSON},
+		ConfigSetting{AppliesTo: Tracy, Key: OmitTimeSetting, Value: true},
+		ConfigSetting{AppliesTo: Tracy, Key: SourceSetting, Value: false},
+		ConfigSetting{AppliesTo: Audit, Key: DestinationSetting, Value: audit},
+	)
+	config.traceIds = set.NewSet[string]()
+	tests := []struct {
+		name      string
+		id        LogID
+		level     slog.Level
+		overrides string
+		w         *bytes.Buffer
+		wantRe    string
+	}{
+		{
+			name:   "normal",
+			id:     Norm,
+			level:  slog.LevelInfo,
+			w:      normal,
+			wantRe: `^{"level":"INFO","msg":"handled","a":1,"g":{"b":2}}` + "\n$",
+		},
+		{
+			name:   "normal-below-level",
+			id:     Norm,
+			level:  slog.LevelWarn,
+			w:      normal,
+			wantRe: "^$",
+		},
+		{
+			name:      "normal-override",
+			id:        Norm,
+			level:     slog.LevelWarn,
+			overrides: "github.com/bruceesmith/logger=debug",
+			w:         normal,
+			wantRe:    `^{"level":"INFO","msg":"handled","a":1,"g":{"b":2}}` + "\n$",
+		},
+		{
+			name:   "trace",
+			id:     Tracy,
+			level:  LevelTrace,
+			w:      trace,
+			wantRe: `^{"level":"INFO","msg":"handled","a":1,"g":{"b":2}}` + "\n$",
+		},
+		{
+			name:   "trace-disabled",
+			id:     Tracy,
+			level:  slog.LevelDebug,
+			w:      trace,
+			wantRe: "^$",
+		},
+		{
+			name:   "named",
+			id:     id,
+			level:  slog.LevelDebug,
+			w:      other,
+			wantRe: "^$",
+		},
+		{
+			name:      "named-override",
+			id:        id,
+			level:     slog.LevelDebug,
+			overrides: "other=info",
+			w:         other,
+			wantRe:    `^{"level":"INFO","msg":"handled","a":1,"g":{"b":2}}` + "\n$",
+		},
+		{
+			name:   "audit",
+			id:     Audit,
+			level:  slog.LevelError,
+			w:      audit,
+			wantRe: `^{"time":".+","level":"INFO","msg":"handled","a":1,"g":{"b":2},"seq":1,"prev":"0+","hash":"[0-9a-f]+"}` + "\n$",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.w.Reset()
+			SetLevel(tt.level)
+			if err := SetLevelOverrides(tt.overrides); err != nil {
+				t.Fatalf("SetLevelOverrides() error = %v", err)
+			}
+			slog.New(Handler(tt.id)).With("a", 1).WithGroup("g").Info("handled", "b", 2)
+			ok, err := regexp.MatchString(tt.wantRe, tt.w.String())
+			if !ok {
+				t.Errorf("Handler() got %s want %s error %v", tt.w.String(), tt.wantRe, err)
+			}
+		})
+	}
+}
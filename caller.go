@@ -0,0 +1,106 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"log/slog"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// maxHelperDepth limits how far up the stack callerPC looks past helpers
+const maxHelperDepth = 32
+
+var (
+	// helpers are the names of functions marked by Helper
+	helpers     sync.Map
+	helperCount atomic.Int32
+)
+
+// callerPC returns the program counter of the function skip frames above the
+// caller of callerPC, passing over any functions marked by Helper
+func callerPC(skip int) uintptr {
+	if helperCount.Load() == 0 {
+		var pcs [1]uintptr
+		runtime.Callers(skip+2, pcs[:]) // skip [Callers, callerPC]
+		return pcs[0]
+	}
+	var pcs [maxHelperDepth]uintptr
+	n := runtime.Callers(skip+2, pcs[:]) // skip [Callers, callerPC]
+	for i := range n {
+		frame, _ := runtime.CallersFrames(pcs[i : i+1]).Next()
+		if _, helper := helpers.Load(frame.Function); !helper {
+			return pcs[i]
+		}
+	}
+	return pcs[0]
+}
+
+// Helper marks the calling function as a logging helper, in the same way as
+// testing.T.Helper. Records emitted by a helper, or by functions it calls, are
+// attributed to the first caller which is not itself a helper
+func Helper() {
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:]) // skip [Callers, Helper]
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	if _, marked := helpers.LoadOrStore(frame.Function, struct{}{}); !marked {
+		helperCount.Add(1)
+	}
+}
+
+// Wrapper emits log records on behalf of functions which wrap this package,
+// attributing each record to a caller further up the stack
+type Wrapper struct {
+	skip int
+}
+
+// WithCallerSkip returns a Wrapper which attributes records to the caller n
+// frames above the function calling its methods. WithCallerSkip(0) behaves like
+// the package-level functions, and WithCallerSkip(1) is suitable for a function
+// which wraps logging and is called directly by application code
+func WithCallerSkip(n int) Wrapper {
+	return Wrapper{skip: n}
+}
+
+// Debug emits a debug log
+func (w Wrapper) Debug(msg string, args ...any) {
+	emit(slog.Default().Handler(), "", slog.LevelDebug, w.skip+1, msg, args...)
+}
+
+// Error emits an error log
+func (w Wrapper) Error(msg string, args ...any) {
+	emit(slog.Default().Handler(), "", slog.LevelError, w.skip+1, msg, args...)
+}
+
+// Info emits an info log
+func (w Wrapper) Info(msg string, args ...any) {
+	emit(slog.Default().Handler(), "", slog.LevelInfo, w.skip+1, msg, args...)
+}
+
+// LogTo emits a log entry at the given level to the identified logger
+func (w Wrapper) LogTo(id LogID, l slog.Level, msg string, args ...any) {
+	logTo(w.skip+1, id, l, msg, args...)
+}
+
+// Trace emits one log entry if trace level logging is enabled
+func (w Wrapper) Trace(msg string, args ...any) {
+	if tracing(LevelTrace) {
+		trace(w.skip+1, LevelTrace, msg, args...)
+	}
+}
+
+// TraceID emits one log entry if tracing is enabled for the requested ID
+func (w Wrapper) TraceID(id string, msg string, args ...any) {
+	if traceIDEnabled(id) {
+		countTraceID(id)
+		trace(w.skip+1, LevelTrace, msg, args...)
+	}
+}
+
+// Warn emits a warning log
+func (w Wrapper) Warn(msg string, args ...any) {
+	emit(slog.Default().Handler(), "", slog.LevelWarn, w.skip+1, msg, args...)
+}
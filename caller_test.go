@@ -0,0 +1,126 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"testing"
+
+	set "github.com/deckarep/golang-set/v2"
+)
+
+// pcHandler remembers the PC of the last record it handles
+type pcHandler struct {
+	pc uintptr
+}
+
+func (h *pcHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *pcHandler) Handle(_ context.Context, r slog.Record) error {
+	h.pc = r.PC
+	return nil
+}
+
+func (h *pcHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *pcHandler) WithGroup(string) slog.Handler { return h }
+
+// line returns the source line of the caller of line
+func line() int {
+	_, _, l, _ := runtime.Caller(1)
+	return l
+}
+
+// helped logs on behalf of its caller after marking itself as a helper
+func helped(msg string) {
+	Helper()
+	Info(msg)
+}
+
+// wrapped logs on behalf of its caller using WithCallerSkip
+func wrapped(msg string) {
+	WithCallerSkip(1).Info(msg)
+}
+
+// wrappedTrace traces on behalf of its caller using WithCallerSkip
+func wrappedTrace(msg string) {
+	WithCallerSkip(1).TraceID("wrapped", msg)
+}
+
+func TestCaller(t *testing.T) {
+	save := config
+	saveDefault := slog.Default()
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		slog.SetDefault(saveDefault)
+		level.Set(saveLevel)
+	}()
+	h := &pcHandler{}
+	slog.SetDefault(slog.New(h))
+	config.traceLogger = slog.New(h)
+	config.traceIds = set.NewSet("wrapped")
+	SetLevel(LevelTrace)
+	tests := []struct {
+		name string
+		log  func() int
+	}{
+		{
+			name: "debug",
+			log:  func() int { Debug("msg"); return line() },
+		},
+		{
+			name: "info",
+			log:  func() int { Info("msg"); return line() },
+		},
+		{
+			name: "warn",
+			log:  func() int { Warn("msg"); return line() },
+		},
+		{
+			name: "error",
+			log:  func() int { Error("msg"); return line() },
+		},
+		{
+			name: "trace",
+			log:  func() int { Trace("msg"); return line() },
+		},
+		{
+			name: "logto",
+			log:  func() int { LogTo(Tracy, LevelTrace, "msg"); return line() },
+		},
+		{
+			name: "helper",
+			log:  func() int { helped("msg"); return line() },
+		},
+		{
+			name: "skip",
+			log:  func() int { wrapped("msg"); return line() },
+		},
+		{
+			name: "skip-trace",
+			log:  func() int { wrappedTrace("msg"); return line() },
+		},
+		{
+			name: "skip-zero",
+			log:  func() int { WithCallerSkip(0).Warn("msg"); return line() },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h.pc = 0
+			want := tt.log()
+			if h.pc == 0 {
+				t.Fatalf("%s got no record", tt.name)
+			}
+			frame, _ := runtime.CallersFrames([]uintptr{h.pc}).Next()
+			if frame.Line != want {
+				t.Errorf("%s got line %d (%s) want %d", tt.name, frame.Line, frame.Function, want)
+			}
+		})
+	}
+}
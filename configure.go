@@ -5,18 +5,22 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"sync/atomic"
 
 	set "github.com/deckarep/golang-set/v2"
 )
 
-//go:generate go tool -modfile=tools/go.mod stringer -type LogID
 //go:generate go tool -modfile=tools/go.mod stringer -type SettingKey
 
 // loggerConfig is the modifiable settings of a logger
 type loggerConfig struct {
 	Destination io.Writer
+	Fallback    io.Writer
 	Format      Format
 	OmitTime    bool
+	Sinks       []Sink
+	Source      Source
+	StackTrace  slog.Leveler
 }
 
 // configuration of this package
@@ -32,8 +36,37 @@ var (
 	defaultNormalDestination = os.Stdout
 	defaultTraceDestination  = os.Stderr
 	level                    slog.LevelVar
+	traceLevel               traceThreshold
 )
 
+// traceThreshold is the minimum level of the trace logger. Until it is
+// configured independently, it follows the level set by SetLevel
+type traceThreshold struct {
+	independent atomic.Bool
+	level       slog.LevelVar
+}
+
+// Level returns the minimum level of the trace logger
+func (t *traceThreshold) Level() slog.Level {
+	if t.independent.Load() {
+		return t.level.Level()
+	}
+	return level.Level()
+}
+
+// set configures the level of the trace logger independently of SetLevel
+func (t *traceThreshold) set(l slog.Level) {
+	t.level.Set(l)
+	t.independent.Store(true)
+}
+
+// tracing reports whether the trace logger is enabled for a level. Tracing
+// requires a trace logger level at or below LevelTrace
+func tracing(l slog.Level) bool {
+	threshold := traceLevel.Level()
+	return threshold <= LevelTrace && l >= threshold
+}
+
 // Format determines the format of each log entry
 type Format string
 
@@ -48,8 +81,12 @@ type LogID int
 const (
 	Norm  LogID = iota // The normal logger
 	Tracy              // The trace logger
+	Audit              // The audit logger
 )
 
+// firstNamed is the LogID given to the first logger registered by RegisterLogger
+const firstNamed = Audit + 1
+
 // SettingKey defines a logger setting that can be set or
 // changed via the Configure function
 type SettingKey int
@@ -58,8 +95,23 @@ const (
 	DestinationSetting SettingKey = iota // Output writer / destination for a logger
 	FormatSetting                        // Format of log entries
 	OmitTimeSetting                      // Whether a timestamp is included in log entries
+	SinksSetting                         // Additional destinations for a logger
+	LevelSetting                         // Minimum level of records emitted by a logger
+	SourceSetting                        // Whether log entries include their source location
+	StackTraceSetting                    // Minimum level of log entries which include a stack trace
+	FallbackSetting                      // Output writer used when a write to the destination fails
 )
 
+// Sink is an additional destination for a logger. Each record emitted by
+// the logger is written to its Destination as well as to every one of its
+// Sinks whose Level is enabled for the record
+type Sink struct {
+	Destination io.Writer    // Output writer for the sink
+	Format      Format       // Format of log entries written to the sink
+	Level       slog.Leveler // Minimum level of records written to the sink; if nil, the level of the logger
+	OmitTime    bool         // Whether the timestamp is omitted from log entries
+}
+
 // ConfigSetting is an argument to Configure()
 type ConfigSetting struct {
 	AppliesTo LogID      // Logger whose setting is set/changed
@@ -78,18 +130,13 @@ func init() {
 			Destination: os.Stderr,
 			Format:      Text,
 			OmitTime:    false,
+			Source:      Source{JSON: true},
 		},
 		traceIds: set.NewSet[string](),
-		traceLogger: slog.New(
-			textHandler(defaultTraceDestination, true),
-		),
 	}
+	config.traceLogger = slog.New(handler(config.Trace, true))
 	level.Set(slog.LevelInfo)
-	slog.SetDefault(
-		slog.New(
-			textHandler(defaultNormalDestination, false),
-		),
-	)
+	slog.SetDefault(slog.New(handler(config.Normal, false)))
 }
 
 // Configure sets or changes attributes of either the normal
@@ -98,8 +145,14 @@ func Configure(setting ...ConfigSetting) error {
 	for _, s := range setting {
 		switch s.AppliesTo {
 		case Norm, Tracy:
+		case Audit:
+			if s.Key != DestinationSetting {
+				return fmt.Errorf("the %s setting of the %s logger cannot be changed", s.Key.String(), s.AppliesTo.String())
+			}
 		default:
-			return fmt.Errorf("there is no logger identied as %s", s.AppliesTo.String())
+			if _, ok := lookup(s.AppliesTo); !ok {
+				return fmt.Errorf("there is no logger identied as %s", s.AppliesTo.String())
+			}
 		}
 		switch s.Key {
 		case DestinationSetting:
@@ -120,6 +173,66 @@ func Configure(setting ...ConfigSetting) error {
 				return fmt.Errorf("unknown imit time value %v", s.Value)
 			}
 			omitTime(s.AppliesTo, b)
+		case SinksSetting:
+			sinks, ok := s.Value.([]Sink)
+			if !ok {
+				return fmt.Errorf("unknown sinks value %v", s.Value)
+			}
+			for i, sk := range sinks {
+				if sk.Destination == nil {
+					return fmt.Errorf("sink %d has no destination", i)
+				}
+				if sk.Format != JSON && sk.Format != Text {
+					return fmt.Errorf("unknown sink Format value %v", sk.Format)
+				}
+			}
+			sinkList(s.AppliesTo, sinks)
+		case LevelSetting:
+			var l slog.Level
+			switch v := s.Value.(type) {
+			case slog.Level:
+				l = v
+			case LogLevel:
+				l = slog.Level(v)
+			default:
+				return fmt.Errorf("unknown level value %v", s.Value)
+			}
+			levels(s.AppliesTo, l)
+		case SourceSetting:
+			var src Source
+			switch v := s.Value.(type) {
+			case Source:
+				src = v
+			case bool:
+				src = Source{JSON: v, Text: v}
+			default:
+				return fmt.Errorf("unknown source value %v", s.Value)
+			}
+			if src.Paths < FullPaths || src.Paths > RelativePaths {
+				return fmt.Errorf("unknown source Paths value %v", src.Paths)
+			}
+			sources(s.AppliesTo, src)
+		case StackTraceSetting:
+			var l slog.Leveler
+			switch v := s.Value.(type) {
+			case nil:
+			case slog.Level:
+				l = v
+			case LogLevel:
+				l = slog.Level(v)
+			default:
+				return fmt.Errorf("unknown stack trace level %v", s.Value)
+			}
+			stackTraces(s.AppliesTo, l)
+		case FallbackSetting:
+			var w io.Writer
+			if s.Value != nil {
+				var ok bool
+				if w, ok = s.Value.(io.Writer); !ok {
+					return fmt.Errorf("unknown fallback destination %v", s.Value)
+				}
+			}
+			fallbacks(s.AppliesTo, w)
 		default:
 			return fmt.Errorf("there is no configuration setting called %s", s.Key.String())
 		}
@@ -138,29 +251,23 @@ func formats(log LogID, f Format) {
 		if f != config.Trace.Format {
 			traceFormat(f)
 		}
+	default:
+		if n, ok := lookup(log); ok {
+			n.configure(func(lc *loggerConfig) { lc.Format = f })
+		}
 	}
 }
 
 // normalFormat adjusts the format (JSON or text) of the normal logger
 func normalFormat(f Format) {
 	config.Normal.Format = f
-	switch f {
-	case JSON:
-		slog.SetDefault(slog.New(jsonHandler(config.Normal.Destination, false)))
-	case Text:
-		slog.SetDefault(slog.New(textHandler(config.Normal.Destination, false)))
-	}
+	rebuildNormal()
 }
 
 // traceFormat adjusts the format (JSON or text) of the trace logger
 func traceFormat(f Format) {
 	config.Trace.Format = f
-	switch f {
-	case JSON:
-		config.traceLogger = slog.New(jsonHandler(config.Trace.Destination, true))
-	case Text:
-		config.traceLogger = slog.New(textHandler(config.Trace.Destination, true))
-	}
+	config.traceLogger = slog.New(handler(config.Trace, true))
 }
 
 // destination adjusts the output writer of loggers
@@ -174,29 +281,25 @@ func destination(log LogID, w io.Writer) {
 		if w != config.Trace.Destination {
 			traceDestination(w)
 		}
+	case Audit:
+		auditDestination(w)
+	default:
+		if n, ok := lookup(log); ok {
+			n.configure(func(lc *loggerConfig) { lc.Destination = w })
+		}
 	}
 }
 
 // normalDestination adjusts the normal logger's writer
 func normalDestination(w io.Writer) {
 	config.Normal.Destination = w
-	switch config.Normal.Format {
-	case JSON:
-		slog.SetDefault(slog.New(jsonHandler(w, false)))
-	case Text:
-		slog.SetDefault(slog.New(textHandler(w, false)))
-	}
+	rebuildNormal()
 }
 
 // traceDestination adjusts the trace logger's writer
 func traceDestination(w io.Writer) {
 	config.Trace.Destination = w
-	switch config.Trace.Format {
-	case JSON:
-		config.traceLogger = slog.New(jsonHandler(w, true))
-	case Text:
-		config.traceLogger = slog.New(textHandler(w, true))
-	}
+	config.traceLogger = slog.New(handler(config.Trace, true))
 }
 
 // omitTime determines if either logger will include timestamps
@@ -206,5 +309,96 @@ func omitTime(log LogID, omit bool) {
 		config.Normal.OmitTime = omit
 	case Tracy:
 		config.Trace.OmitTime = omit
+	default:
+		if n, ok := lookup(log); ok {
+			n.configure(func(lc *loggerConfig) { lc.OmitTime = omit })
+		}
+	}
+}
+
+// sinkList replaces the additional sinks of loggers
+func sinkList(log LogID, sinks []Sink) {
+	switch log {
+	case Norm:
+		config.Normal.Sinks = sinks
+		rebuildNormal()
+	case Tracy:
+		config.Trace.Sinks = sinks
+		config.traceLogger = slog.New(handler(config.Trace, true))
+	default:
+		if n, ok := lookup(log); ok {
+			n.configure(func(lc *loggerConfig) { lc.Sinks = sinks })
+		}
+	}
+}
+
+// levels adjusts the minimum level of loggers
+func levels(log LogID, l slog.Level) {
+	switch log {
+	case Norm:
+		level.Set(l)
+	case Tracy:
+		traceLevel.set(l)
+	default:
+		if n, ok := lookup(log); ok {
+			n.level.Set(l)
+		}
+	}
+}
+
+// sources adjusts whether loggers include the source location of records
+func sources(log LogID, src Source) {
+	switch log {
+	case Norm:
+		config.Normal.Source = src
+		rebuildNormal()
+	case Tracy:
+		config.Trace.Source = src
+		config.traceLogger = slog.New(handler(config.Trace, true))
+	default:
+		if n, ok := lookup(log); ok {
+			n.configure(func(lc *loggerConfig) { lc.Source = src })
+		}
 	}
 }
+
+// stackTraces adjusts the minimum level of records for which loggers include
+// a stack trace. A nil level disables stack traces
+func stackTraces(log LogID, l slog.Leveler) {
+	switch log {
+	case Norm:
+		config.Normal.StackTrace = l
+		rebuildNormal()
+	case Tracy:
+		config.Trace.StackTrace = l
+		config.traceLogger = slog.New(handler(config.Trace, true))
+	default:
+		if n, ok := lookup(log); ok {
+			n.configure(func(lc *loggerConfig) { lc.StackTrace = l })
+		}
+	}
+}
+
+// fallbacks adjusts the writer to which loggers write records that could not
+// be written to their destination. A nil writer disables the fallback
+func fallbacks(log LogID, w io.Writer) {
+	switch log {
+	case Norm:
+		config.Normal.Fallback = w
+		rebuildNormal()
+	case Tracy:
+		config.Trace.Fallback = w
+		config.traceLogger = slog.New(handler(config.Trace, true))
+	default:
+		if n, ok := lookup(log); ok {
+			n.configure(func(lc *loggerConfig) { lc.Fallback = w })
+		}
+	}
+}
+
+// rebuildNormal replaces the default slog logger after a change to the settings
+// of the normal logger
+func rebuildNormal() {
+	slog.SetDefault(slog.New(handler(config.Normal, false)))
+	redirectStdLog()
+}
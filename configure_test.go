@@ -3,6 +3,8 @@ package logger
 import (
 	"bytes"
 	"io"
+	"log/slog"
+	"regexp"
 	"testing"
 )
 
@@ -93,6 +95,71 @@ func TestConfigure(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "bad-sinks",
+			args: args{
+				setting: []ConfigSetting{
+					{
+						AppliesTo: Norm,
+						Key:       SinksSetting,
+						Value:     "any",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "sink-no-destination",
+			args: args{
+				setting: []ConfigSetting{
+					{
+						AppliesTo: Norm,
+						Key:       SinksSetting,
+						Value:     []Sink{{Format: JSON}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "sink-bad-format",
+			args: args{
+				setting: []ConfigSetting{
+					{
+						AppliesTo: Tracy,
+						Key:       SinksSetting,
+						Value:     []Sink{{Destination: &bytes.Buffer{}, Format: "xml"}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad-level",
+			args: args{
+				setting: []ConfigSetting{
+					{
+						AppliesTo: Norm,
+						Key:       LevelSetting,
+						Value:     "any",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad-fallback",
+			args: args{
+				setting: []ConfigSetting{
+					{
+						AppliesTo: Norm,
+						Key:       FallbackSetting,
+						Value:     "stderr",
+					},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "destination",
 			args: args{
@@ -132,13 +199,83 @@ func TestConfigure(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "sinks",
+			args: args{
+				setting: []ConfigSetting{
+					{
+						AppliesTo: Norm,
+						Key:       SinksSetting,
+						Value:     []Sink{{Destination: &bytes.Buffer{}, Format: JSON, Level: slog.LevelDebug}},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "level-tracy",
+			args: args{
+				setting: []ConfigSetting{
+					{
+						AppliesTo: Tracy,
+						Key:       LevelSetting,
+						Value:     LevelTrace,
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "level",
+			args: args{
+				setting: []ConfigSetting{
+					{
+						AppliesTo: Norm,
+						Key:       LevelSetting,
+						Value:     LogLevel(slog.LevelWarn),
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "fallback",
+			args: args{
+				setting: []ConfigSetting{
+					{
+						AppliesTo: Tracy,
+						Key:       FallbackSetting,
+						Value:     &bytes.Buffer{},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "fallback-off",
+			args: args{
+				setting: []ConfigSetting{
+					{
+						AppliesTo: Norm,
+						Key:       FallbackSetting,
+						Value:     nil,
+					},
+				},
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
+		save := config
+		saveLevel := level.Level()
 		t.Run(tt.name, func(t *testing.T) {
 			if err := Configure(tt.args.setting...); (err != nil) != tt.wantErr {
 				t.Errorf("Configure() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
+		config = save
+		level.Set(saveLevel)
+		traceLevel.independent.Store(false)
 	}
 }
 
@@ -398,3 +535,317 @@ func Test_omitTime(t *testing.T) {
 		config = save
 	}
 }
+
+func Test_sinkList(t *testing.T) {
+	type args struct {
+		log   LogID
+		sinks []Sink
+	}
+	tests := []struct {
+		name string
+		args args
+	}{
+		{
+			name: "norm",
+			args: args{
+				log:   Norm,
+				sinks: []Sink{{Destination: &bytes.Buffer{}, Format: JSON}},
+			},
+		},
+		{
+			name: "tracy",
+			args: args{
+				log:   Tracy,
+				sinks: []Sink{{Destination: &bytes.Buffer{}, Format: Text}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		save := config
+		saveDefault := slog.Default()
+		t.Run(tt.name, func(t *testing.T) {
+			sinkList(tt.args.log, tt.args.sinks)
+			if tt.args.log == Norm && len(config.Normal.Sinks) != len(tt.args.sinks) {
+				t.Errorf("sinkList() got = %v want =%v", config.Normal.Sinks, tt.args.sinks)
+			}
+			if tt.args.log == Tracy && len(config.Trace.Sinks) != len(tt.args.sinks) {
+				t.Errorf("sinkList() got = %v want =%v", config.Trace.Sinks, tt.args.sinks)
+			}
+		})
+		config = save
+		slog.SetDefault(saveDefault)
+	}
+}
+
+func TestConfigure_sinks(t *testing.T) {
+	save := config
+	saveDefault := slog.Default()
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		slog.SetDefault(saveDefault)
+		level.Set(saveLevel)
+	}()
+	var (
+		primary = &bytes.Buffer{}
+		debug   = &bytes.Buffer{}
+		errs    = &bytes.Buffer{}
+	)
+	SetLevel(slog.LevelInfo)
+	err := Configure(
+		ConfigSetting{AppliesTo: Norm, Key: DestinationSetting, Value: primary},
+		ConfigSetting{AppliesTo: Norm, Key: FormatSetting, Value: Text},
+		ConfigSetting{
+			AppliesTo: Norm,
+			Key:       SinksSetting,
+			Value: []Sink{
+				{Destination: debug, Format: JSON, Level: slog.LevelDebug, OmitTime: true},
+				{Destination: errs, Format: Text, Level: slog.LevelError},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	Debug("debug")
+	Info("info")
+	Error("error")
+	tests := []struct {
+		name   string
+		got    string
+		wantRe string
+	}{
+		{
+			name:   "primary",
+			got:    primary.String(),
+			wantRe: "^time=.+ level=INFO msg=info\ntime=.+ level=ERROR msg=error\n$",
+		},
+		{
+			name:   "debug",
+			got:    debug.String(),
+			wantRe: `^{"level":"DEBUG","msg":"debug"}\n{"level":"INFO","msg":"info"}\n{"level":"ERROR","msg":"error"}\n$`,
+		},
+		{
+			name:   "errors",
+			got:    errs.String(),
+			wantRe: "^time=.+ level=ERROR msg=error\n$",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := regexp.MatchString(tt.wantRe, tt.got)
+			if !ok {
+				t.Errorf("Configure() sink got %s want %s error %v", tt.got, tt.wantRe, err)
+			}
+		})
+	}
+}
+
+func TestConfigure_sinkLevel(t *testing.T) {
+	save := config
+	saveDefault := slog.Default()
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		slog.SetDefault(saveDefault)
+		level.Set(saveLevel)
+		resetNamed()
+	}()
+	access, err := RegisterLogger("sink-level", Sink{Destination: &bytes.Buffer{}})
+	if err != nil {
+		t.Fatalf("RegisterLogger() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		log  LogID
+		emit func()
+	}{
+		{
+			name: "norm",
+			log:  Norm,
+			emit: func() { Debug("debug") },
+		},
+		{
+			name: "named",
+			log:  access,
+			emit: func() { LogTo(access, slog.LevelDebug, "debug") },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := Configure(
+				ConfigSetting{AppliesTo: tt.log, Key: LevelSetting, Value: slog.LevelInfo},
+				ConfigSetting{AppliesTo: tt.log, Key: SinksSetting, Value: []Sink{{Destination: w, Format: Text, OmitTime: true}}},
+			)
+			if err != nil {
+				t.Fatalf("Configure() error = %v", err)
+			}
+			tt.emit()
+			if w.Len() != 0 {
+				t.Errorf("Configure() sink got %s want nothing at level INFO", w.String())
+			}
+			if err := Configure(ConfigSetting{AppliesTo: tt.log, Key: LevelSetting, Value: slog.LevelDebug}); err != nil {
+				t.Fatalf("Configure() error = %v", err)
+			}
+			tt.emit()
+			if got, want := w.String(), "level=DEBUG msg=debug\n"; got != want {
+				t.Errorf("Configure() sink got %q want %q", got, want)
+			}
+		})
+	}
+}
+
+func Test_levels(t *testing.T) {
+	defer resetNamed()
+	id, err := RegisterLogger("access", Sink{})
+	if err != nil {
+		t.Fatalf("RegisterLogger() error = %v", err)
+	}
+	n, _ := lookup(id)
+	type args struct {
+		log LogID
+		l   slog.Level
+	}
+	tests := []struct {
+		name string
+		args args
+		got  func() slog.Level
+	}{
+		{
+			name: "norm",
+			args: args{
+				log: Norm,
+				l:   slog.LevelWarn,
+			},
+			got: level.Level,
+		},
+		{
+			name: "tracy",
+			args: args{
+				log: Tracy,
+				l:   LevelTrace - 2,
+			},
+			got: traceLevel.Level,
+		},
+		{
+			name: "named",
+			args: args{
+				log: id,
+				l:   slog.LevelDebug,
+			},
+			got: n.level.Level,
+		},
+	}
+	for _, tt := range tests {
+		saveLevel := level.Level()
+		t.Run(tt.name, func(t *testing.T) {
+			levels(tt.args.log, tt.args.l)
+			if got := tt.got(); got != tt.args.l {
+				t.Errorf("levels() got = %v want =%v", got, tt.args.l)
+			}
+		})
+		level.Set(saveLevel)
+		traceLevel.independent.Store(false)
+	}
+}
+
+func Test_traceThreshold(t *testing.T) {
+	defer traceLevel.independent.Store(false)
+	saveLevel := level.Level()
+	defer level.Set(saveLevel)
+	tests := []struct {
+		name        string
+		global      slog.Level
+		independent *slog.Level
+		lev         slog.Level
+		want        bool
+	}{
+		{
+			name:   "follows-global",
+			global: LevelTrace,
+			lev:    LevelTrace,
+			want:   true,
+		},
+		{
+			name:   "follows-global-off",
+			global: slog.LevelDebug,
+			lev:    LevelTrace,
+			want:   false,
+		},
+		{
+			name:        "independent",
+			global:      slog.LevelWarn,
+			independent: func() *slog.Level { l := LevelTrace; return &l }(),
+			lev:         LevelTrace,
+			want:        true,
+		},
+		{
+			name:        "independent-off",
+			global:      LevelTrace,
+			independent: func() *slog.Level { l := slog.LevelInfo; return &l }(),
+			lev:         LevelTrace,
+			want:        false,
+		},
+		{
+			name:        "verbose",
+			global:      slog.LevelWarn,
+			independent: func() *slog.Level { l := LevelTrace - 2; return &l }(),
+			lev:         LevelTrace - 2,
+			want:        true,
+		},
+		{
+			name:        "too-verbose",
+			global:      slog.LevelWarn,
+			independent: func() *slog.Level { l := LevelTrace - 2; return &l }(),
+			lev:         LevelTrace - 3,
+			want:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traceLevel.independent.Store(false)
+			SetLevel(tt.global)
+			if tt.independent != nil {
+				traceLevel.set(*tt.independent)
+			}
+			if got := tracing(tt.lev); got != tt.want {
+				t.Errorf("tracing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigure_traceLevel(t *testing.T) {
+	save := config
+	saveDefault := slog.Default()
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		slog.SetDefault(saveDefault)
+		level.Set(saveLevel)
+		traceLevel.independent.Store(false)
+	}()
+	var (
+		norm  = &bytes.Buffer{}
+		tracy = &bytes.Buffer{}
+	)
+	RedirectStandard(norm)
+	RedirectTrace(tracy)
+	err := Configure(
+		ConfigSetting{AppliesTo: Norm, Key: LevelSetting, Value: slog.LevelWarn},
+		ConfigSetting{AppliesTo: Tracy, Key: LevelSetting, Value: LevelTrace},
+	)
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	Debug("debug")
+	Trace("trace")
+	if norm.Len() != 0 {
+		t.Errorf("Configure() normal logger got %s", norm.String())
+	}
+	ok, err := regexp.MatchString(`^time=.+ level=TRACE msg=trace\n$`, tracy.String())
+	if !ok {
+		t.Errorf("Configure() trace logger got %s error %v", tracy.String(), err)
+	}
+}
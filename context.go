@@ -0,0 +1,64 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// contextKey is the key of the attributes added to a context by ContextWith
+type contextKey struct{}
+
+// ContextWith returns a context carrying attributes, such as a request ID,
+// which are added to every record emitted with the context by DebugContext,
+// InfoContext, WarnContext, ErrorContext and TraceIDContext. Arguments are
+// key-value pairs or slog.Attr values, as for Info
+func ContextWith(ctx context.Context, args ...any) context.Context {
+	r := slog.Record{}
+	r.Add(args...)
+	attrs := contextAttrs(ctx)
+	attrs = attrs[:len(attrs):len(attrs)]
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return context.WithValue(ctx, contextKey{}, attrs)
+}
+
+// contextAttrs returns the attributes added to a context by ContextWith
+func contextAttrs(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	return attrs
+}
+
+// DebugContext emits a debug log with the attributes of a context
+func DebugContext(ctx context.Context, msg string, args ...any) {
+	emitContext(ctx, slog.Default().Handler(), "", slog.LevelDebug, 1, msg, args...)
+}
+
+// ErrorContext emits an error log with the attributes of a context
+func ErrorContext(ctx context.Context, msg string, args ...any) {
+	emitContext(ctx, slog.Default().Handler(), "", slog.LevelError, 1, msg, args...)
+}
+
+// InfoContext emits an info log with the attributes of a context
+func InfoContext(ctx context.Context, msg string, args ...any) {
+	emitContext(ctx, slog.Default().Handler(), "", slog.LevelInfo, 1, msg, args...)
+}
+
+// TraceIDContext emits one log entry with the attributes of a context if
+// tracing is enabled for the requested ID
+func TraceIDContext(ctx context.Context, id string, msg string, args ...any) {
+	if traceIDEnabled(id) {
+		countTraceID(id)
+		traceContext(ctx, 1, LevelTrace, msg, args...)
+	}
+}
+
+// WarnContext emits a warning log with the attributes of a context
+func WarnContext(ctx context.Context, msg string, args ...any) {
+	emitContext(ctx, slog.Default().Handler(), "", slog.LevelWarn, 1, msg, args...)
+}
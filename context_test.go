@@ -0,0 +1,95 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	set "github.com/deckarep/golang-set/v2"
+)
+
+func TestContextWith(t *testing.T) {
+	save := config
+	saveDefault := slog.Default()
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		slog.SetDefault(saveDefault)
+		level.Set(saveLevel)
+	}()
+	parent := ContextWith(context.Background(), "request_id", "r1")
+	child := ContextWith(parent, slog.String("user", "bob"))
+	_ = ContextWith(parent, "sibling", true)
+	tests := []struct {
+		name   string
+		level  slog.Level
+		log    func()
+		wantRe string
+	}{
+		{
+			name:   "debug",
+			level:  slog.LevelDebug,
+			log:    func() { DebugContext(child, "debug", "n", 1) },
+			wantRe: "^level=DEBUG msg=debug request_id=r1 user=bob n=1\n$",
+		},
+		{
+			name:   "debug-below-level",
+			level:  slog.LevelInfo,
+			log:    func() { DebugContext(child, "debug") },
+			wantRe: "^$",
+		},
+		{
+			name:   "info",
+			level:  slog.LevelInfo,
+			log:    func() { InfoContext(parent, "info") },
+			wantRe: "^level=INFO msg=info request_id=r1\n$",
+		},
+		{
+			name:   "warn",
+			level:  slog.LevelInfo,
+			log:    func() { WarnContext(child, "warn") },
+			wantRe: "^level=WARN msg=warn request_id=r1 user=bob\n$",
+		},
+		{
+			name:   "error",
+			level:  slog.LevelInfo,
+			log:    func() { ErrorContext(context.Background(), "error") },
+			wantRe: "^level=ERROR msg=error\n$",
+		},
+		{
+			name:   "trace",
+			level:  LevelTrace,
+			log:    func() { TraceIDContext(child, "ctx", "trace") },
+			wantRe: "^level=TRACE msg=trace request_id=r1 user=bob\n$",
+		},
+		{
+			name:   "trace-disabled",
+			level:  LevelTrace,
+			log:    func() { TraceIDContext(child, "other", "trace") },
+			wantRe: "^$",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			SetLevel(tt.level)
+			config.Normal.OmitTime = true
+			config.Trace.OmitTime = true
+			config.Trace.Source = Source{}
+			config.traceIds = set.NewSet("ctx")
+			slog.SetDefault(slog.New(textHandler(w, false)))
+			config.traceLogger = slog.New(textHandler(w, true))
+			tt.log()
+			ok, err := regexp.MatchString(tt.wantRe, w.String())
+			if !ok {
+				t.Errorf("%s() got %s want %s error %v", tt.name, w.String(), tt.wantRe, err)
+			}
+		})
+	}
+}
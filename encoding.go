@@ -0,0 +1,144 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Set is a convenience method for pflag.Value
+func (f *Format) Set(s string) error {
+	return f.UnmarshalText([]byte(s))
+}
+
+// String is a convenience method for pflag.Value
+func (f *Format) String() string {
+	return string(*f)
+}
+
+// Type is a convenience method for pflag.Value
+func (f *Format) Type() string {
+	return "Format"
+}
+
+// LogValue implements slog.LogValuer
+func (f Format) LogValue() slog.Value {
+	return slog.StringValue(string(f))
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (f Format) MarshalText() ([]byte, error) {
+	return []byte(f), nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (f Format) MarshalYAML() (any, error) {
+	return string(f), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (f *Format) UnmarshalText(text []byte) error {
+	switch Format(strings.ToLower(string(text))) {
+	case JSON:
+		*f = JSON
+	case Text:
+		*f = Text
+	default:
+		return fmt.Errorf("unknown logger Format value %s", string(text))
+	}
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (f *Format) UnmarshalYAML(unmarshal func(any) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return f.UnmarshalText([]byte(s))
+}
+
+// LogValue implements slog.LogValuer
+func (i LogID) LogValue() slog.Value {
+	return slog.StringValue(i.String())
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (i LogID) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (i LogID) MarshalYAML() (any, error) {
+	return i.String(), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the name of
+// a built-in logger or of one registered by RegisterLogger
+func (i *LogID) UnmarshalText(text []byte) error {
+	name := string(text)
+	for _, id := range []LogID{Norm, Tracy, Audit} {
+		if strings.EqualFold(name, id.String()) {
+			*i = id
+			return nil
+		}
+	}
+	id, ok := LoggerID(name)
+	if !ok {
+		return fmt.Errorf("there is no logger called %s", name)
+	}
+	*i = id
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (i *LogID) UnmarshalYAML(unmarshal func(any) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return i.UnmarshalText([]byte(s))
+}
+
+// LogValue implements slog.LogValuer
+func (i SettingKey) LogValue() slog.Value {
+	return slog.StringValue(i.String())
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (i SettingKey) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (i SettingKey) MarshalYAML() (any, error) {
+	return i.String(), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (i *SettingKey) UnmarshalText(text []byte) error {
+	name := string(text)
+	for k := SettingKey(0); ; k++ {
+		s := k.String()
+		if strings.HasPrefix(s, "SettingKey(") {
+			return fmt.Errorf("there is no configuration setting called %s", name)
+		}
+		if strings.EqualFold(name, s) {
+			*i = k
+			return nil
+		}
+	}
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (i *SettingKey) UnmarshalYAML(unmarshal func(any) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return i.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,298 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+// yamlUnmarshal stands in for the unmarshal function passed by a YAML decoder
+func yamlUnmarshal(jason string) func(any) error {
+	return func(v any) error {
+		return json.Unmarshal([]byte(jason), v)
+	}
+}
+
+func TestEncoding_json(t *testing.T) {
+	defer resetNamed()
+	if _, err := RegisterLogger("access", Sink{}); err != nil {
+		t.Fatalf("RegisterLogger() error = %v", err)
+	}
+	type settings struct {
+		Level   LogLevel
+		Format  Format
+		Logger  LogID
+		Named   LogID
+		Key     SettingKey
+		TraceID Traces
+	}
+	want := settings{
+		Level:   LogLevel(slog.LevelInfo + 2),
+		Format:  JSON,
+		Logger:  Tracy,
+		Named:   firstNamed,
+		Key:     OmitTimeSetting,
+		TraceID: Traces{"db", "http"},
+	}
+	jason, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	wantJSON := `{"Level":"INFO+2","Format":"json","Logger":"Tracy","Named":"access","Key":"OmitTimeSetting","TraceID":["db","http"]}`
+	if string(jason) != wantJSON {
+		t.Errorf("json.Marshal() = %s, want %s", jason, wantJSON)
+	}
+	var got settings
+	if err := json.Unmarshal(jason, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("json.Unmarshal() = %v, want %v", got, want)
+	}
+}
+
+func TestEncoding_text(t *testing.T) {
+	defer resetNamed()
+	if _, err := RegisterLogger("access", Sink{}); err != nil {
+		t.Fatalf("RegisterLogger() error = %v", err)
+	}
+	ll := LogLevel(slog.LevelDebug)
+	format := Text
+	id := LogID(firstNamed)
+	key := SinksSetting
+	traces := Traces{"one", "two"}
+	tests := []struct {
+		name     string
+		value    encoding.TextMarshaler
+		text     string
+		into     encoding.TextUnmarshaler
+		bad      string
+		wantText string
+	}{
+		{
+			name:  "loglevel",
+			value: ll,
+			text:  "debug-2",
+			into:  new(LogLevel),
+			bad:   "loud",
+		},
+		{
+			name:  "format",
+			value: format,
+			text:  "JSON",
+			into:  new(Format),
+			bad:   "xml",
+		},
+		{
+			name:  "logid",
+			value: id,
+			text:  "ACCESS",
+			into:  new(LogID),
+			bad:   "security",
+		},
+		{
+			name:  "settingkey",
+			value: key,
+			text:  "formatsetting",
+			into:  new(SettingKey),
+			bad:   "ColourSetting",
+		},
+		{
+			name:  "traces",
+			value: traces,
+			text:  "db,http",
+			into:  new(Traces),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := tt.value.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText() error = %v", err)
+			}
+			if err := tt.into.UnmarshalText(text); err != nil {
+				t.Fatalf("UnmarshalText() error = %v", err)
+			}
+			if got := reflect.ValueOf(tt.into).Elem().Interface(); !reflect.DeepEqual(got, tt.value) {
+				t.Errorf("UnmarshalText() = %v, want %v", got, tt.value)
+			}
+			if err := tt.into.UnmarshalText([]byte(tt.text)); err != nil {
+				t.Errorf("UnmarshalText(%s) error = %v", tt.text, err)
+			}
+			if tt.bad != "" {
+				if err := tt.into.UnmarshalText([]byte(tt.bad)); err == nil {
+					t.Errorf("UnmarshalText(%s) error = nil, want error", tt.bad)
+				}
+			}
+		})
+	}
+}
+
+func TestEncoding_yaml(t *testing.T) {
+	type yamlMarshaler interface {
+		MarshalYAML() (any, error)
+	}
+	type yamlUnmarshaler interface {
+		UnmarshalYAML(func(any) error) error
+	}
+	tests := []struct {
+		name      string
+		value     yamlMarshaler
+		want      any
+		node      string
+		into      yamlUnmarshaler
+		wantValue any
+	}{
+		{
+			name:      "loglevel",
+			value:     LogLevel(slog.LevelWarn),
+			want:      "WARN",
+			node:      `"warn+1"`,
+			into:      new(LogLevel),
+			wantValue: LogLevel(slog.LevelWarn + 1),
+		},
+		{
+			name:      "format",
+			value:     JSON,
+			want:      "json",
+			node:      `"Text"`,
+			into:      new(Format),
+			wantValue: Text,
+		},
+		{
+			name:      "logid",
+			value:     Norm,
+			want:      "Norm",
+			node:      `"audit"`,
+			into:      new(LogID),
+			wantValue: Audit,
+		},
+		{
+			name:      "settingkey",
+			value:     LevelSetting,
+			want:      "LevelSetting",
+			node:      `"DestinationSetting"`,
+			into:      new(SettingKey),
+			wantValue: DestinationSetting,
+		},
+		{
+			name:      "traces-sequence",
+			value:     Traces{"db"},
+			want:      []string{"db"},
+			node:      `["db","http"]`,
+			into:      new(Traces),
+			wantValue: Traces{"db", "http"},
+		},
+		{
+			name:      "traces-string",
+			value:     Traces{"db", "http"},
+			want:      []string{"db", "http"},
+			node:      `"db,http"`,
+			into:      new(Traces),
+			wantValue: Traces{"db", "http"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.value.MarshalYAML()
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MarshalYAML() = %v, error = %v, want %v", got, err, tt.want)
+			}
+			if err := tt.into.UnmarshalYAML(yamlUnmarshal(tt.node)); err != nil {
+				t.Fatalf("UnmarshalYAML() error = %v", err)
+			}
+			if got := reflect.ValueOf(tt.into).Elem().Interface(); !reflect.DeepEqual(got, tt.wantValue) {
+				t.Errorf("UnmarshalYAML() = %v, want %v", got, tt.wantValue)
+			}
+			if err := tt.into.UnmarshalYAML(yamlUnmarshal(`{}`)); err == nil {
+				t.Errorf("UnmarshalYAML() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestEncoding_LogValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value slog.LogValuer
+		want  string
+	}{
+		{
+			name:  "loglevel",
+			value: LogLevel(LevelTrace),
+			want:  "TRACE",
+		},
+		{
+			name:  "format",
+			value: Text,
+			want:  "text",
+		},
+		{
+			name:  "logid",
+			value: Tracy,
+			want:  "Tracy",
+		},
+		{
+			name:  "settingkey",
+			value: FormatSetting,
+			want:  "FormatSetting",
+		},
+		{
+			name:  "traces",
+			value: Traces{"db", "http"},
+			want:  "db,http",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.value.LogValue().String(); got != tt.want {
+				t.Errorf("LogValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Format
+		wantErr bool
+	}{
+		{
+			name: "json",
+			s:    "Json",
+			want: JSON,
+		},
+		{
+			name: "text",
+			s:    "text",
+			want: Text,
+		},
+		{
+			name:    "bad",
+			s:       "xml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f Format
+			if err := f.Set(tt.s); (err != nil) != tt.wantErr {
+				t.Fatalf("Format.Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if f != tt.want || f.String() != string(tt.want) {
+				t.Errorf("Format.Set() = %v, want %v", f, tt.want)
+			}
+			if f.Type() != "Format" {
+				t.Errorf("Format.Type() = %v, want Format", f.Type())
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+)
+
+// ErrorHandler is called with the error when a logger cannot write a record to
+// its destination. It must not log to the same logger
+type ErrorHandler func(id LogID, err error)
+
+var errorHandler atomic.Pointer[ErrorHandler]
+
+// SetErrorHandler sets the function called when a logger cannot write a record,
+// and returns the function it replaces. Write errors are ignored when there is
+// no ErrorHandler, which is the default
+func SetErrorHandler(h ErrorHandler) ErrorHandler {
+	var previous *ErrorHandler
+	if h == nil {
+		previous = errorHandler.Swap(nil)
+	} else {
+		previous = errorHandler.Swap(&h)
+	}
+	if previous == nil {
+		return nil
+	}
+	return *previous
+}
+
+// reportError passes a write error to the ErrorHandler, if there is one
+func reportError(id LogID, err error) {
+	if h := errorHandler.Load(); h != nil {
+		(*h)(id, err)
+	}
+}
+
+// fallbackHandler is a slog.Handler which writes a record to a fallback
+// destination when its primary destination fails
+type fallbackHandler struct {
+	slog.Handler
+	fallback slog.Handler
+}
+
+// withFallback adds a fallback handler to the handler of a destination. A nil
+// fallback leaves the handler unchanged
+func withFallback(h slog.Handler, fallback slog.Handler) slog.Handler {
+	if fallback == nil {
+		return h
+	}
+	return &fallbackHandler{Handler: h, fallback: fallback}
+}
+
+// Handle writes the record to the primary destination and, if that fails, to
+// the fallback destination
+func (f *fallbackHandler) Handle(ctx context.Context, r slog.Record) error {
+	err := f.Handler.Handle(ctx, r)
+	if err == nil {
+		return nil
+	}
+	if fbErr := f.fallback.Handle(ctx, r); fbErr != nil {
+		return errors.Join(err, fbErr)
+	}
+	return partialError{err}
+}
+
+// WithAttrs returns a fallbackHandler whose handlers both include the attributes
+func (f *fallbackHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &fallbackHandler{Handler: f.Handler.WithAttrs(attrs), fallback: f.fallback.WithAttrs(attrs)}
+}
+
+// WithGroup returns a fallbackHandler whose handlers both qualify attributes with the group
+func (f *fallbackHandler) WithGroup(name string) slog.Handler {
+	return &fallbackHandler{Handler: f.Handler.WithGroup(name), fallback: f.fallback.WithGroup(name)}
+}
@@ -0,0 +1,189 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	set "github.com/deckarep/golang-set/v2"
+)
+
+func TestConfigure_fallback(t *testing.T) {
+	save := config
+	saveLevel := level.Level()
+	saveDefault := slog.Default()
+	saveHandler := SetErrorHandler(nil)
+	defer func() {
+		config = save
+		level.Set(saveLevel)
+		slog.SetDefault(saveDefault)
+		SetErrorHandler(saveHandler)
+	}()
+	tests := []struct {
+		name        string
+		log         LogID
+		destination io.Writer
+		fallback    io.Writer
+		emit        func()
+		wantErrs    int
+		wantRe      string
+		wantDropped uint64
+	}{
+		{
+			name:        "norm",
+			log:         Norm,
+			destination: failingWriter{},
+			fallback:    &bytes.Buffer{},
+			emit:        func() { Info("hello", "one", 1) },
+			wantErrs:    1,
+			wantRe:      `^level=INFO msg=hello one=1\n$`,
+		},
+		{
+			name:        "norm-no-fallback",
+			log:         Norm,
+			destination: failingWriter{},
+			emit:        func() { Info("hello") },
+			wantErrs:    1,
+			wantDropped: 1,
+		},
+		{
+			name:        "norm-failing-fallback",
+			log:         Norm,
+			destination: failingWriter{},
+			fallback:    failingWriter{},
+			emit:        func() { Info("hello") },
+			wantErrs:    1,
+			wantDropped: 1,
+		},
+		{
+			name:        "norm-ok",
+			log:         Norm,
+			destination: &bytes.Buffer{},
+			fallback:    &bytes.Buffer{},
+			emit:        func() { Info("hello") },
+		},
+		{
+			name:        "tracy",
+			log:         Tracy,
+			destination: failingWriter{},
+			fallback:    &bytes.Buffer{},
+			emit:        func() { TraceID("fallback", "traced", "two", 2) },
+			wantErrs:    1,
+			wantRe:      `^level=TRACE msg=traced two=2\n$`,
+		},
+		{
+			name:        "tracy-no-fallback",
+			log:         Tracy,
+			destination: failingWriter{},
+			emit:        func() { Trace("traced") },
+			wantErrs:    1,
+			wantDropped: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []LogID
+			SetErrorHandler(func(id LogID, err error) {
+				ids = append(ids, id)
+			})
+			SetLevel(LevelTrace)
+			config.traceIds = set.NewSet("fallback")
+			err := Configure(
+				ConfigSetting{AppliesTo: tt.log, Key: DestinationSetting, Value: tt.destination},
+				ConfigSetting{AppliesTo: tt.log, Key: OmitTimeSetting, Value: true},
+				ConfigSetting{AppliesTo: tt.log, Key: FallbackSetting, Value: tt.fallback},
+			)
+			if err != nil {
+				t.Fatalf("Configure() error %v", err)
+			}
+			before := ReadMetrics()
+			tt.emit()
+			after := ReadMetrics()
+			if len(ids) != tt.wantErrs {
+				t.Fatalf("ErrorHandler got %d calls want %d", len(ids), tt.wantErrs)
+			}
+			for _, id := range ids {
+				if id != tt.log {
+					t.Errorf("ErrorHandler got %s want %s", id, tt.log)
+				}
+			}
+			if b, ok := tt.fallback.(*bytes.Buffer); ok {
+				if tt.wantRe == "" && b.Len() != 0 {
+					t.Errorf("fallback got %s want nothing", b.String())
+				}
+				if ok, err := regexp.MatchString(tt.wantRe, b.String()); tt.wantRe != "" && !ok {
+					t.Errorf("fallback got %s want %s error %v", b.String(), tt.wantRe, err)
+				}
+			}
+			name := tt.log.String()
+			if got := after.Failed[name] - before.Failed[name]; got != uint64(tt.wantErrs) {
+				t.Errorf("ReadMetrics() failed got %d want %d", got, tt.wantErrs)
+			}
+			if got := after.Dropped[name] - before.Dropped[name]; got != tt.wantDropped {
+				t.Errorf("ReadMetrics() dropped got %d want %d", got, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestConfigure_fallbackNamed(t *testing.T) {
+	saveHandler := SetErrorHandler(nil)
+	defer SetErrorHandler(saveHandler)
+	id, err := RegisterLogger("fallback-test", Sink{Destination: failingWriter{}, Format: JSON})
+	if err != nil {
+		t.Fatalf("RegisterLogger() error %v", err)
+	}
+	var got []LogID
+	SetErrorHandler(func(id LogID, err error) {
+		got = append(got, id)
+	})
+	w := &bytes.Buffer{}
+	if err := Configure(ConfigSetting{AppliesTo: id, Key: FallbackSetting, Value: w}); err != nil {
+		t.Fatalf("Configure() error %v", err)
+	}
+	LogTo(id, slog.LevelWarn, "named", "three", 3)
+	if len(got) != 1 || got[0] != id {
+		t.Errorf("ErrorHandler got %v want [%s]", got, id)
+	}
+	want := `^{"time":".+","level":"WARN","msg":"named","three":3}\n$`
+	if ok, err := regexp.MatchString(want, w.String()); !ok {
+		t.Errorf("fallback got %s want %s error %v", w.String(), want, err)
+	}
+}
+
+func TestSetErrorHandler(t *testing.T) {
+	saveHandler := SetErrorHandler(nil)
+	defer func() {
+		SetErrorHandler(saveHandler)
+		auditDestination(nil)
+	}()
+	if previous := SetErrorHandler(nil); previous != nil {
+		t.Errorf("SetErrorHandler() got a previous handler want nil")
+	}
+	var calls int
+	h := func(id LogID, err error) {
+		if id != Audit || err == nil {
+			t.Errorf("ErrorHandler got %s %v want %s and an error", id, err, Audit)
+		}
+		calls++
+	}
+	SetErrorHandler(h)
+	auditDestination(failingWriter{})
+	AuditRecord("unwritten")
+	if calls != 1 {
+		t.Errorf("ErrorHandler got %d calls want 1", calls)
+	}
+	if previous := SetErrorHandler(nil); previous == nil {
+		t.Errorf("SetErrorHandler() got nil want the previous handler")
+	}
+	AuditRecord("unreported")
+	if calls != 1 {
+		t.Errorf("ErrorHandler got %d calls after removal want 1", calls)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// fanout is a slog.Handler that passes each record to several handlers,
+// each of which applies its own level, format and destination
+type fanout struct {
+	handlers []slog.Handler
+}
+
+// Enabled reports whether any of the handlers is enabled for the level
+func (f *fanout) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, h := range f.handlers {
+		if h.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+// partialError is returned by a fanout when a record was written to some of
+// its destinations but not to others
+type partialError struct {
+	error
+}
+
+// Unwrap returns the errors of the destinations which failed
+func (p partialError) Unwrap() error {
+	return p.error
+}
+
+// Handle passes the record to each handler that is enabled for its level. The
+// first handler, for the logger's own destination, also receives records that
+// have been enabled by a level override
+func (f *fanout) Handle(ctx context.Context, r slog.Record) error {
+	var (
+		errs    []error
+		written bool
+	)
+	for i, h := range f.handlers {
+		if (i == 0 && overridden(ctx)) || h.Enabled(ctx, r.Level) {
+			err := h.Handle(ctx, r.Clone())
+			errs = append(errs, err)
+			written = written || err == nil || errors.As(err, new(partialError))
+		}
+	}
+	err := errors.Join(errs...)
+	if err != nil && written {
+		return partialError{err}
+	}
+	return err
+}
+
+// WithAttrs returns a fanout whose handlers all include the attributes
+func (f *fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(f.handlers))
+	for i, h := range f.handlers {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return &fanout{handlers: handlers}
+}
+
+// WithGroup returns a fanout whose handlers all qualify attributes with the group
+func (f *fanout) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(f.handlers))
+	for i, h := range f.handlers {
+		handlers[i] = h.WithGroup(name)
+	}
+	return &fanout{handlers: handlers}
+}
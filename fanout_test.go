@@ -0,0 +1,76 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func Test_fanout_Enabled(t *testing.T) {
+	f := &fanout{
+		handlers: []slog.Handler{
+			slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelWarn}),
+			slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelInfo}),
+		},
+	}
+	tests := []struct {
+		name  string
+		level slog.Level
+		want  bool
+	}{
+		{
+			name:  "debug",
+			level: slog.LevelDebug,
+			want:  false,
+		},
+		{
+			name:  "info",
+			level: slog.LevelInfo,
+			want:  true,
+		},
+		{
+			name:  "error",
+			level: slog.LevelError,
+			want:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.Enabled(context.Background(), tt.level); got != tt.want {
+				t.Errorf("fanout.Enabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_fanout_Handle(t *testing.T) {
+	var (
+		warn = &bytes.Buffer{}
+		info = &bytes.Buffer{}
+	)
+	f := &fanout{
+		handlers: []slog.Handler{
+			slog.NewTextHandler(warn, &slog.HandlerOptions{Level: slog.LevelWarn}),
+			slog.NewJSONHandler(info, &slog.HandlerOptions{Level: slog.LevelInfo}),
+		},
+	}
+	g := f.WithAttrs([]slog.Attr{slog.Int("one", 1)}).WithGroup("grp")
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+	r.AddAttrs(slog.Int("two", 2))
+	if err := g.Handle(context.Background(), r); err != nil {
+		t.Fatalf("fanout.Handle() error = %v", err)
+	}
+	if warn.Len() != 0 {
+		t.Errorf("fanout.Handle() warn got %s want nothing", warn.String())
+	}
+	want := `"msg":"msg","one":1,"grp":{"two":2}}` + "\n"
+	if got := info.String(); !bytes.HasSuffix([]byte(got), []byte(want)) {
+		t.Errorf("fanout.Handle() info got %s want suffix %s", got, want)
+	}
+}
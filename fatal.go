@@ -0,0 +1,114 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+)
+
+// syncer is a destination which can commit buffered writes, such as an os.File
+type syncer interface {
+	Sync() error
+}
+
+// flusher is a destination which buffers writes, such as a bufio.Writer
+type flusher interface {
+	Flush() error
+}
+
+var (
+	shutdownLock  sync.Mutex
+	shutdownHooks []func()
+	exitFunc      = os.Exit
+)
+
+// RegisterShutdownHook adds a function to be run by Fatal and Fatalf before
+// the program exits. Hooks run in the reverse order of their registration,
+// like deferred calls, and are run only once
+func RegisterShutdownHook(hook func()) {
+	shutdownLock.Lock()
+	defer shutdownLock.Unlock()
+	shutdownHooks = append(shutdownHooks, hook)
+}
+
+// SetExitFunc replaces the function called by Fatal and Fatalf to end the
+// program, which is os.Exit by default, and returns the function it replaces.
+// Tests can use it to observe a fatal error without exiting
+func SetExitFunc(exit func(code int)) func(code int) {
+	shutdownLock.Lock()
+	defer shutdownLock.Unlock()
+	previous := exitFunc
+	exitFunc = exit
+	return previous
+}
+
+// Fatal emits a log at LevelFatal, runs the shutdown hooks, flushes all
+// destinations and exits with status 1
+func Fatal(msg string, args ...any) {
+	emit(slog.Default().Handler(), "", LevelFatal, 1, msg, args...)
+	shutdown(1)
+}
+
+// Fatalf emits a log at LevelFatal whose message is formatted by fmt.Sprintf,
+// runs the shutdown hooks, flushes all destinations and exits with status 1
+func Fatalf(format string, args ...any) {
+	emitf(slog.Default().Handler(), "", LevelFatal, 1, format, args...)
+	shutdown(1)
+}
+
+// Panic emits a log at LevelPanic, flushes all destinations and then panics
+// with the message
+func Panic(msg string, args ...any) {
+	emit(slog.Default().Handler(), "", LevelPanic, 1, msg, args...)
+	flush()
+	panic(msg)
+}
+
+// shutdown runs the shutdown hooks, flushes all destinations and exits
+func shutdown(code int) {
+	shutdownLock.Lock()
+	hooks := shutdownHooks
+	shutdownHooks = nil
+	exit := exitFunc
+	shutdownLock.Unlock()
+	for _, hook := range slices.Backward(hooks) {
+		hook()
+	}
+	flush()
+	exit(code)
+}
+
+// flush commits any buffered writes of the destinations of every logger
+func flush() {
+	var writers []io.Writer
+	add := func(lc loggerConfig) {
+		writers = append(writers, lc.Destination, lc.Fallback)
+		for _, s := range lc.Sinks {
+			writers = append(writers, s.Destination)
+		}
+	}
+	add(config.Normal)
+	add(config.Trace)
+	namedLock.RLock()
+	for _, n := range named {
+		add(n.config)
+	}
+	namedLock.RUnlock()
+	auditLog.lock.Lock()
+	writers = append(writers, auditLog.destination)
+	auditLog.lock.Unlock()
+	for _, w := range writers {
+		switch d := w.(type) {
+		case flusher:
+			_ = d.Flush()
+		case syncer:
+			_ = d.Sync()
+		}
+	}
+}
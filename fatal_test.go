@@ -0,0 +1,105 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"log/slog"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestFatal(t *testing.T) {
+	save := config
+	saveDefault := slog.Default()
+	saveLevel := level.Level()
+	saveExit := SetExitFunc(nil)
+	defer func() {
+		config = save
+		slog.SetDefault(saveDefault)
+		level.Set(saveLevel)
+		SetExitFunc(saveExit)
+	}()
+	tests := []struct {
+		name   string
+		fatal  func()
+		wantRe string
+	}{
+		{
+			name:   "fatal",
+			fatal:  func() { Fatal("cannot continue", "reason", "broken") },
+			wantRe: "^level=FATAL msg=\"cannot continue\" reason=broken\n$",
+		},
+		{
+			name:   "fatalf",
+			fatal:  func() { Fatalf("cannot open %s", "config.yaml") },
+			wantRe: "^level=FATAL msg=\"cannot open config.yaml\"\n$",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				b     bytes.Buffer
+				w     = bufio.NewWriter(&b)
+				code  = -1
+				hooks []int
+			)
+			SetLevel(slog.LevelInfo)
+			_ = Configure(
+				ConfigSetting{AppliesTo: Norm, Key: DestinationSetting, Value: w},
+				ConfigSetting{AppliesTo: Norm, Key: OmitTimeSetting, Value: true},
+			)
+			SetExitFunc(func(c int) {
+				code = c
+				if b.Len() == 0 {
+					t.Errorf("%s() exited before flushing", tt.name)
+				}
+			})
+			RegisterShutdownHook(func() { hooks = append(hooks, 1) })
+			RegisterShutdownHook(func() { hooks = append(hooks, 2) })
+			tt.fatal()
+			if code != 1 {
+				t.Errorf("%s() exit code got %d want 1", tt.name, code)
+			}
+			if !slices.Equal(hooks, []int{2, 1}) {
+				t.Errorf("%s() shutdown hooks got %v want [2 1]", tt.name, hooks)
+			}
+			ok, err := regexp.MatchString(tt.wantRe, b.String())
+			if !ok {
+				t.Errorf("%s() got %s want %s error %v", tt.name, b.String(), tt.wantRe, err)
+			}
+		})
+	}
+}
+
+func TestPanic(t *testing.T) {
+	save := config
+	saveDefault := slog.Default()
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		slog.SetDefault(saveDefault)
+		level.Set(saveLevel)
+	}()
+	var b bytes.Buffer
+	w := bufio.NewWriter(&b)
+	SetLevel(slog.LevelInfo)
+	_ = Configure(
+		ConfigSetting{AppliesTo: Norm, Key: DestinationSetting, Value: w},
+		ConfigSetting{AppliesTo: Norm, Key: OmitTimeSetting, Value: true},
+	)
+	defer func() {
+		if p := recover(); p != "out of range" {
+			t.Errorf("Panic() got panic %v want out of range", p)
+		}
+		want := "level=PANIC msg=\"out of range\" index=7\n"
+		if b.String() != want {
+			t.Errorf("Panic() got %s want %s", b.String(), want)
+		}
+	}()
+	Panic("out of range", "index", 7)
+}
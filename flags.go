@@ -0,0 +1,229 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/urfave/cli/v3"
+)
+
+// Names of the flags returned by CLIFlags
+const (
+	LogLevelFlagName    = "log-level"
+	LogFormatFlagName   = "log-format"
+	TraceFormatFlagName = "trace-format"
+	TraceIDsFlagName    = "trace-ids"
+	OmitTimeFlagName    = "log-omit-time"
+	LogFileFlagName     = "log-file"
+	TraceFileFlagName   = "trace-file"
+)
+
+// TracesFlag is useful for using Traces as a command-line flag in CLI applications.
+// The flag can be repeated, and each value can be a comma-separated list
+type TracesFlag = cli.FlagBase[Traces, cli.NoConfig, tracesValue]
+
+// FormatFlag is useful for using a Format as a command-line flag in CLI applications
+type FormatFlag = cli.FlagBase[Format, cli.NoConfig, formatValue]
+
+// OmitTimeFlag is useful for choosing whether log entries include timestamps in CLI applications
+type OmitTimeFlag = cli.BoolFlag
+
+// LogDestinationFlag is useful for choosing the destination of a logger in CLI
+// applications. Its value is stdout, stderr or the path of a file
+type LogDestinationFlag = cli.FlagBase[string, cli.NoConfig, destinationValue]
+
+// CLIFlags returns flags for all of the logging options, each of which can also be
+// set by an environment variable. Pass Before as the Before hook of the command
+// to apply them
+func CLIFlags() []cli.Flag {
+	return []cli.Flag{
+		&LogLevelFlag{
+			Name:    LogLevelFlagName,
+			Usage:   "level of logging",
+			Value:   LogLevel(slog.LevelInfo),
+			Sources: cli.EnvVars("LOG_LEVEL"),
+		},
+		&FormatFlag{
+			Name:    LogFormatFlagName,
+			Usage:   "format of log entries (text or json)",
+			Value:   Text,
+			Sources: cli.EnvVars("LOG_FORMAT"),
+		},
+		&FormatFlag{
+			Name:    TraceFormatFlagName,
+			Usage:   "format of trace entries (text or json)",
+			Value:   Text,
+			Sources: cli.EnvVars("TRACE_FORMAT"),
+		},
+		&TracesFlag{
+			Name:    TraceIDsFlagName,
+			Usage:   "comma-separated trace IDs to enable",
+			Sources: cli.EnvVars("TRACE_IDS"),
+		},
+		&OmitTimeFlag{
+			Name:    OmitTimeFlagName,
+			Usage:   "omit timestamps from log entries",
+			Sources: cli.EnvVars("LOG_OMIT_TIME"),
+		},
+		&LogDestinationFlag{
+			Name:      LogFileFlagName,
+			Usage:     "destination of log entries (stdout, stderr or a file)",
+			Value:     "stdout",
+			TakesFile: true,
+			Sources:   cli.EnvVars("LOG_FILE"),
+		},
+		&LogDestinationFlag{
+			Name:      TraceFileFlagName,
+			Usage:     "destination of trace entries (stdout, stderr or a file)",
+			Value:     "stderr",
+			TakesFile: true,
+			Sources:   cli.EnvVars("TRACE_FILE"),
+		},
+	}
+}
+
+// Before applies the flags returned by CLIFlags, and is intended to be used
+// as (or called from) the Before hook of a cli.Command. Flags which have not
+// been set on the command line or by an environment variable are ignored
+func Before(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	var o Options
+	if cmd.IsSet(LogLevelFlagName) {
+		o.Level, _ = cmd.Value(LogLevelFlagName).(LogLevel)
+	} else {
+		o.Level = LogLevel(level.Level())
+	}
+	if cmd.IsSet(LogFormatFlagName) {
+		o.Format, _ = cmd.Value(LogFormatFlagName).(Format)
+	}
+	if cmd.IsSet(TraceFormatFlagName) {
+		o.TraceFormat, _ = cmd.Value(TraceFormatFlagName).(Format)
+	}
+	if cmd.IsSet(TraceIDsFlagName) {
+		o.TraceIDs, _ = cmd.Value(TraceIDsFlagName).(Traces)
+	}
+	if cmd.IsSet(OmitTimeFlagName) {
+		o.OmitTime = cmd.Bool(OmitTimeFlagName)
+	} else {
+		o.OmitTime = config.Normal.OmitTime
+	}
+	if cmd.IsSet(LogFileFlagName) {
+		o.LogFile = cmd.String(LogFileFlagName)
+	}
+	if cmd.IsSet(TraceFileFlagName) {
+		o.TraceFile = cmd.String(TraceFileFlagName)
+	}
+	return ctx, o.Apply()
+}
+
+// tracesValue supports command-line Traces arguments
+type tracesValue struct {
+	destination *Traces
+}
+
+// Create returns a value which implements the golang flag.Value and flag.Getter interfaces
+func (t tracesValue) Create(val Traces, p *Traces, _ cli.NoConfig) cli.Value {
+	*p = val
+	return &tracesValue{destination: p}
+}
+
+// Get fetches the Traces value
+func (t tracesValue) Get() any {
+	return *t.destination
+}
+
+// Set adds a comma-separated list of trace IDs to a TracesFlag
+func (t tracesValue) Set(s string) error {
+	return t.destination.Set(s)
+}
+
+// String returns a string representation of Traces
+func (t tracesValue) String() string {
+	if t.destination == nil {
+		return ""
+	}
+	text, _ := t.destination.MarshalText()
+	return string(text)
+}
+
+// ToString returns a string representation of Traces
+func (t tracesValue) ToString(tr Traces) string {
+	text, _ := tr.MarshalText()
+	return string(text)
+}
+
+// formatValue supports command-line Format arguments
+type formatValue struct {
+	destination *Format
+}
+
+// Create returns a value which implements the golang flag.Value and flag.Getter interfaces
+func (f formatValue) Create(val Format, p *Format, _ cli.NoConfig) cli.Value {
+	*p = val
+	return &formatValue{destination: p}
+}
+
+// Get fetches the Format value
+func (f formatValue) Get() any {
+	return *f.destination
+}
+
+// Set stores a string into a FormatFlag
+func (f formatValue) Set(s string) error {
+	return f.destination.UnmarshalText([]byte(s))
+}
+
+// String returns a string representation of a Format
+func (f formatValue) String() string {
+	if f.destination == nil {
+		return ""
+	}
+	return string(*f.destination)
+}
+
+// ToString returns a string representation of a Format
+func (f formatValue) ToString(format Format) string {
+	return string(format)
+}
+
+// destinationValue supports command-line log destination arguments
+type destinationValue struct {
+	destination *string
+}
+
+// Create returns a value which implements the golang flag.Value and flag.Getter interfaces
+func (d destinationValue) Create(val string, p *string, _ cli.NoConfig) cli.Value {
+	*p = val
+	return &destinationValue{destination: p}
+}
+
+// Get fetches the destination name
+func (d destinationValue) Get() any {
+	return *d.destination
+}
+
+// Set stores a destination name into a LogDestinationFlag
+func (d destinationValue) Set(s string) error {
+	if s == "" {
+		return fmt.Errorf("a log destination cannot be empty")
+	}
+	*d.destination = s
+	return nil
+}
+
+// String returns the destination name
+func (d destinationValue) String() string {
+	if d.destination == nil {
+		return ""
+	}
+	return *d.destination
+}
+
+// ToString returns the destination name
+func (d destinationValue) ToString(s string) string {
+	return s
+}
@@ -0,0 +1,196 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestBefore(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		check   func(t *testing.T)
+		wantErr bool
+	}{
+		{
+			name: "flags",
+			args: []string{
+				"--log-level", "debug",
+				"--log-format", "json",
+				"--trace-format", "JSON",
+				"--trace-ids", "db,http",
+				"--trace-ids", "grpc",
+				"--log-omit-time",
+				"--log-file", filepath.Join(dir, "log"),
+				"--trace-file", "stdout",
+			},
+			check: func(t *testing.T) {
+				if level.Level() != slog.LevelDebug {
+					t.Errorf("Before() level = %v", level.Level())
+				}
+				if config.Normal.Format != JSON || config.Trace.Format != JSON {
+					t.Errorf("Before() formats = %v, %v", config.Normal.Format, config.Trace.Format)
+				}
+				if !config.traceIds.Contains("db", "http", "grpc") {
+					t.Errorf("Before() trace IDs = %v", config.traceIds)
+				}
+				if !config.Normal.OmitTime {
+					t.Errorf("Before() OmitTime = false")
+				}
+				if f, ok := config.Normal.Destination.(*os.File); !ok || f.Name() != filepath.Join(dir, "log") {
+					t.Errorf("Before() destination = %v", config.Normal.Destination)
+				}
+				if config.Trace.Destination != os.Stdout {
+					t.Errorf("Before() trace destination = %v", config.Trace.Destination)
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "env",
+			env: map[string]string{
+				"LOG_LEVEL":     "warn",
+				"LOG_FORMAT":    "json",
+				"TRACE_IDS":     "db",
+				"LOG_OMIT_TIME": "true",
+			},
+			check: func(t *testing.T) {
+				if level.Level() != slog.LevelWarn {
+					t.Errorf("Before() level = %v", level.Level())
+				}
+				if config.Normal.Format != JSON || config.Trace.Format != Text {
+					t.Errorf("Before() formats = %v, %v", config.Normal.Format, config.Trace.Format)
+				}
+				if !config.traceIds.Contains("db") {
+					t.Errorf("Before() trace IDs = %v", config.traceIds)
+				}
+				if !config.Normal.OmitTime {
+					t.Errorf("Before() OmitTime = false")
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "unset",
+			check: func(t *testing.T) {
+				if level.Level() != slog.LevelError {
+					t.Errorf("Before() level = %v", level.Level())
+				}
+				if config.Normal.Destination != defaultNormalDestination {
+					t.Errorf("Before() destination = %v", config.Normal.Destination)
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name:    "bad-format",
+			args:    []string{"--log-format", "xml"},
+			wantErr: true,
+		},
+		{
+			name:    "bad-level",
+			args:    []string{"--log-level", "loud"},
+			wantErr: true,
+		},
+		{
+			name:    "bad-file",
+			args:    []string{"--log-file", filepath.Join(dir, "missing", "log")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		restore := saveState()
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			SetLevel(slog.LevelError)
+			cmd := &cli.Command{
+				Name:      "test",
+				Flags:     CLIFlags(),
+				Before:    Before,
+				Action:    func(context.Context, *cli.Command) error { return nil },
+				Writer:    io.Discard,
+				ErrWriter: io.Discard,
+			}
+			err := cmd.Run(context.Background(), append([]string{"test"}, tt.args...))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Before() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t)
+			}
+		})
+		restore()
+	}
+}
+
+func Test_tracesValue(t *testing.T) {
+	var tr Traces
+	v := tracesValue{}.Create(Traces{"one"}, &tr, cli.NoConfig{})
+	if err := v.Set("two,three"); err != nil {
+		t.Fatalf("tracesValue.Set() error = %v", err)
+	}
+	if got, want := v.Get(), (Traces{"one", "two", "three"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("tracesValue.Get() = %v, want %v", got, want)
+	}
+	if got, want := v.String(), "one,two,three"; got != want {
+		t.Errorf("tracesValue.String() = %v, want %v", got, want)
+	}
+	if got, want := (tracesValue{}).ToString(Traces{"a", "b"}), "a,b"; got != want {
+		t.Errorf("tracesValue.ToString() = %v, want %v", got, want)
+	}
+}
+
+func Test_formatValue(t *testing.T) {
+	var f Format
+	v := formatValue{}.Create(Text, &f, cli.NoConfig{})
+	if err := v.Set("JSON"); err != nil {
+		t.Fatalf("formatValue.Set() error = %v", err)
+	}
+	if got := v.Get(); got != JSON {
+		t.Errorf("formatValue.Get() = %v, want %v", got, JSON)
+	}
+	if err := v.Set("xml"); err == nil {
+		t.Errorf("formatValue.Set() error = nil, want error")
+	}
+	if got := v.String(); got != "json" {
+		t.Errorf("formatValue.String() = %v, want json", got)
+	}
+	if got := (formatValue{}).ToString(Text); got != "text" {
+		t.Errorf("formatValue.ToString() = %v, want text", got)
+	}
+}
+
+func Test_destinationValue(t *testing.T) {
+	var d string
+	v := destinationValue{}.Create("stdout", &d, cli.NoConfig{})
+	if err := v.Set("/var/log/app.log"); err != nil {
+		t.Fatalf("destinationValue.Set() error = %v", err)
+	}
+	if got := v.Get(); got != "/var/log/app.log" {
+		t.Errorf("destinationValue.Get() = %v", got)
+	}
+	if err := v.Set(""); err == nil {
+		t.Errorf("destinationValue.Set() error = nil, want error")
+	}
+	if got := v.String(); got != "/var/log/app.log" {
+		t.Errorf("destinationValue.String() = %v", got)
+	}
+	if got := (destinationValue{}).ToString("stderr"); got != "stderr" {
+		t.Errorf("destinationValue.ToString() = %v, want stderr", got)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"log/slog"
+)
+
+// lazy is an attribute value resolved only when a record is handled
+type lazy func() any
+
+// LogValue implements slog.LogValuer by calling the function
+func (l lazy) LogValue() slog.Value {
+	return slog.AnyValue(l())
+}
+
+// Lazy returns an attribute value which is computed by calling f only if
+// the record containing it is actually handled, for example
+//
+//	logger.TraceID("db", "query", "plan", logger.Lazy(func() any { return explain(q) }))
+//
+// A function which captures variables, as above, is still allocated when the
+// record is not handled; TraceIDFunc avoids even that allocation
+func Lazy(f func() any) slog.LogValuer {
+	return lazy(f)
+}
+
+// TraceIDFunc emits one log entry if tracing is enabled for the requested ID,
+// calling f to build the message and arguments only when it is
+func TraceIDFunc(id string, f func() (msg string, args []any)) {
+	if traceIDEnabled(id) {
+		countTraceID(id)
+		msg, args := f()
+		trace(1, LevelTrace, msg, args...)
+	}
+}
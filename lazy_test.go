@@ -0,0 +1,207 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	set "github.com/deckarep/golang-set/v2"
+)
+
+// expensive counts the number of times it is called
+type expensive struct {
+	calls int
+}
+
+// dump is an expensive function whose result is traced
+func (e *expensive) dump() any {
+	e.calls++
+	return map[string]int{"rows": 3}
+}
+
+func TestLazy(t *testing.T) {
+	save := config
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		level.Set(saveLevel)
+	}()
+	tests := []struct {
+		name      string
+		ids       set.Set[string]
+		lev       slog.Level
+		wantCalls int
+		wantRe    string
+	}{
+		{
+			name:      "enabled",
+			ids:       set.NewSet("db"),
+			lev:       LevelTrace,
+			wantCalls: 1,
+			wantRe:    `^{"time":".+","level":"TRACE","source":{.+},"msg":"query","plan":{"rows":3}}\n$`,
+		},
+		{
+			name:      "disabled-id",
+			ids:       set.NewSet("http"),
+			lev:       LevelTrace,
+			wantCalls: 0,
+			wantRe:    `^$`,
+		},
+		{
+			name:      "disabled-level",
+			ids:       set.NewSet("db"),
+			lev:       slog.LevelInfo,
+			wantCalls: 0,
+			wantRe:    `^$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			SetLevel(tt.lev)
+			config.traceIds = tt.ids
+			config.traceLogger = slog.New(jsonHandler(w, true))
+			e := &expensive{}
+			TraceID("db", "query", "plan", Lazy(e.dump))
+			if e.calls != tt.wantCalls {
+				t.Errorf("Lazy() calls = %d, want %d", e.calls, tt.wantCalls)
+			}
+			ok, err := regexp.MatchString(tt.wantRe, w.String())
+			if !ok {
+				t.Errorf("Lazy() got %s want %s error %v", w.String(), tt.wantRe, err)
+			}
+		})
+	}
+}
+
+func TestTraceIDFunc(t *testing.T) {
+	save := config
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		level.Set(saveLevel)
+	}()
+	tests := []struct {
+		name      string
+		ids       set.Set[string]
+		wantCalls int
+		wantRe    string
+	}{
+		{
+			name:      "enabled",
+			ids:       set.NewSet("all"),
+			wantCalls: 1,
+			wantRe:    `^{"time":".+","level":"TRACE","source":{.+},"msg":"query 3","rows":3}\n$`,
+		},
+		{
+			name:      "disabled",
+			ids:       set.NewSet("http"),
+			wantCalls: 0,
+			wantRe:    `^$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			SetLevel(LevelTrace)
+			config.traceIds = tt.ids
+			config.traceLogger = slog.New(jsonHandler(w, true))
+			calls := 0
+			TraceIDFunc("db", func() (string, []any) {
+				calls++
+				return "query 3", []any{"rows", 3}
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("TraceIDFunc() calls = %d, want %d", calls, tt.wantCalls)
+			}
+			ok, err := regexp.MatchString(tt.wantRe, w.String())
+			if !ok {
+				t.Errorf("TraceIDFunc() got %s want %s error %v", w.String(), tt.wantRe, err)
+			}
+		})
+	}
+}
+
+// disableTracing turns off tracing for the ID used by benchmarks
+func disableTracing(b *testing.B) {
+	save := config
+	saveLevel := level.Level()
+	b.Cleanup(func() {
+		config = save
+		level.Set(saveLevel)
+	})
+	SetLevel(LevelTrace)
+	config.traceIds = set.NewSet("other")
+}
+
+func BenchmarkTraceID_disabled(b *testing.B) {
+	disableTracing(b)
+	e := &expensive{}
+	b.ReportAllocs()
+	for b.Loop() {
+		TraceID("db", "query", "plan", e.dump())
+	}
+}
+
+// plan is an expensive function whose result is traced
+func plan() any {
+	return map[string]int{"rows": 3}
+}
+
+func BenchmarkTraceID_lazyDisabled(b *testing.B) {
+	disableTracing(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		TraceID("db", "query", "plan", Lazy(plan))
+	}
+}
+
+func BenchmarkTraceIDFunc_disabled(b *testing.B) {
+	disableTracing(b)
+	e := &expensive{}
+	b.ReportAllocs()
+	for b.Loop() {
+		TraceIDFunc("db", func() (string, []any) {
+			return "query", []any{"plan", e.dump()}
+		})
+	}
+}
+
+func TestTraceIDFunc_allocs(t *testing.T) {
+	save := config
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		level.Set(saveLevel)
+	}()
+	SetLevel(LevelTrace)
+	config.traceIds = set.NewSet("other")
+	e := &expensive{}
+	tests := []struct {
+		name string
+		f    func()
+	}{
+		{
+			name: "lazy",
+			f:    func() { TraceID("db", "query", "plan", Lazy(plan)) },
+		},
+		{
+			name: "func",
+			f: func() {
+				TraceIDFunc("db", func() (string, []any) { return "query", []any{"plan", e.dump()} })
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if allocs := testing.AllocsPerRun(100, tt.f); allocs != 0 {
+				t.Errorf("disabled allocs = %v, want 0", allocs)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+/*
+Package logexpvar publishes the counts of records emitted by the loggers of
+package [github.com/bruceesmith/logger] as an [expvar] variable.
+
+	if err := logexpvar.Publish(); err != nil {
+		...
+	}
+
+The variable holds the logger.Metrics returned by logger.ReadMetrics, and is
+served as JSON at /debug/vars by the handler which package expvar registers.
+Importing this package, and so package expvar, is what adds that handler to
+http.DefaultServeMux.
+*/
+package logexpvar
+
+import (
+	"expvar"
+	"fmt"
+
+	"github.com/bruceesmith/logger"
+)
+
+// DefaultName is the name of the expvar variable unless another is given to PublishAs
+const DefaultName = "logger"
+
+// Publish publishes the metrics of the loggers as the expvar variable DefaultName
+func Publish() error {
+	return PublishAs(DefaultName)
+}
+
+// PublishAs publishes the metrics of the loggers as the expvar variable name. It
+// returns an error, rather than panicking as expvar.Publish does, if there is
+// already a variable of that name
+func PublishAs(name string) error {
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("there is already an expvar variable called %s", name)
+	}
+	expvar.Publish(name, Var())
+	return nil
+}
+
+// Var returns an expvar.Var holding the metrics of the loggers, for programs
+// which publish their variables in their own way
+func Var() expvar.Var {
+	return expvar.Func(func() any { return logger.ReadMetrics() })
+}
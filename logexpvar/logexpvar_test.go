@@ -0,0 +1,57 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logexpvar
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/bruceesmith/logger"
+)
+
+func TestPublishAs(t *testing.T) {
+	tests := []struct {
+		name    string
+		publish func() error
+		varName string
+		wantErr bool
+	}{
+		{
+			name:    "default",
+			publish: Publish,
+			varName: DefaultName,
+		},
+		{
+			name:    "duplicate",
+			publish: Publish,
+			varName: DefaultName,
+			wantErr: true,
+		},
+		{
+			name:    "named",
+			publish: func() error { return PublishAs("app_logger") },
+			varName: "app_logger",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.publish(); (err != nil) != tt.wantErr {
+				t.Fatalf("PublishAs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			v := expvar.Get(tt.varName)
+			if v == nil {
+				t.Fatalf("expvar.Get(%s) got nil", tt.varName)
+			}
+			var m logger.Metrics
+			if err := json.Unmarshal([]byte(v.String()), &m); err != nil {
+				t.Fatalf("expvar %s got %s error %v", tt.varName, v.String(), err)
+			}
+			if m.Records == nil || m.TraceIDs == nil || m.Failed == nil || m.Dropped == nil {
+				t.Errorf("expvar %s got %s want all counts", tt.varName, v.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+/*
+Package logflag registers the options of package [github.com/bruceesmith/logger] as flags
+of a standard library [flag.FlagSet].
+
+	opts := logflag.BindFlags(flag.CommandLine)
+	flag.Parse()
+	if err := opts.Apply(); err != nil {
+		...
+	}
+*/
+package logflag
+
+import (
+	"flag"
+	"log/slog"
+
+	"github.com/bruceesmith/logger"
+)
+
+// BindFlags registers the logging flags --log-level, --log-format, --trace-format,
+// --trace-ids, --log-omit-time and --log-file with a FlagSet. After the FlagSet has
+// been parsed, calling Apply on the returned Options pushes the flag values into
+// package logger
+func BindFlags(fs *flag.FlagSet) *logger.Options {
+	o := &logger.Options{
+		Level: logger.LogLevel(slog.LevelInfo),
+	}
+	fs.Var(&o.Level, logger.LogLevelFlagName, "level of logging")
+	fs.Var(&o.Format, logger.LogFormatFlagName, "format of log entries (text or json)")
+	fs.Var(&o.TraceFormat, logger.TraceFormatFlagName, "format of trace entries (text or json)")
+	fs.Var(&o.TraceIDs, logger.TraceIDsFlagName, "comma-separated trace IDs to enable")
+	fs.BoolVar(&o.OmitTime, logger.OmitTimeFlagName, false, "omit timestamps from log entries")
+	fs.StringVar(&o.LogFile, logger.LogFileFlagName, "", "destination of log entries (stdout, stderr or a file)")
+	return o
+}
@@ -0,0 +1,109 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logflag
+
+import (
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/bruceesmith/logger"
+)
+
+func TestBindFlags(t *testing.T) {
+	defer func() {
+		logger.SetLevel(slog.LevelInfo)
+		_ = logger.Configure(
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: os.Stdout},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.FormatSetting, Value: logger.Text},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: false},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.FormatSetting, Value: logger.Text},
+		)
+	}()
+	file := filepath.Join(t.TempDir(), "log")
+	tests := []struct {
+		name     string
+		args     []string
+		want     logger.Options
+		wantErr  bool
+		applyErr bool
+	}{
+		{
+			name: "defaults",
+			args: []string{},
+			want: logger.Options{
+				Level: logger.LogLevel(slog.LevelInfo),
+			},
+		},
+		{
+			name: "all",
+			args: []string{
+				"--log-level", "debug",
+				"--log-format", "json",
+				"--trace-format", "text",
+				"--trace-ids", "db,http",
+				"--log-omit-time",
+				"--log-file", file,
+			},
+			want: logger.Options{
+				Level:       logger.LogLevel(slog.LevelDebug),
+				Format:      logger.JSON,
+				TraceFormat: logger.Text,
+				TraceIDs:    logger.Traces{"db", "http"},
+				OmitTime:    true,
+				LogFile:     file,
+			},
+		},
+		{
+			name:    "bad-format",
+			args:    []string{"--log-format", "xml"},
+			wantErr: true,
+		},
+		{
+			name:     "bad-file",
+			args:     []string{"--log-file", filepath.Join(file, "log")},
+			applyErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			o := BindFlags(fs)
+			if err := fs.Parse(tt.args); (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if err := o.Apply(); (err != nil) != tt.applyErr {
+				t.Fatalf("Options.Apply() error = %v, wantErr %v", err, tt.applyErr)
+			}
+			if tt.applyErr {
+				return
+			}
+			if o.Level != tt.want.Level || o.Format != tt.want.Format || o.TraceFormat != tt.want.TraceFormat ||
+				!slices.Equal(o.TraceIDs, tt.want.TraceIDs) || o.OmitTime != tt.want.OmitTime || o.LogFile != tt.want.LogFile {
+				t.Errorf("BindFlags() = %+v, want %+v", *o, tt.want)
+			}
+			if got := logger.Level(); got != (&tt.want.Level).String() {
+				t.Errorf("Options.Apply() level = %v, want %v", got, tt.want.Level)
+			}
+		})
+	}
+	logger.Info("hello")
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if ok, _ := regexp.Match(`^{"level":"INFO","msg":"hello"}\n$`, content); !ok {
+		t.Errorf("Options.Apply() log file contains %s", content)
+	}
+}
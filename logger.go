@@ -6,19 +6,66 @@
 Package logger supports logging and tracing based on the standard library package [log/slog].
 
 Debug, Error, Info and Warn operate like their package slog equivalents, with the level of logging modifiable
-using SetLevel.
+using SetLevel. SetLevelOverrides replaces that level for particular Go packages or named loggers.
+Debugf, Infof, Warnf, Errorf, Tracef and TraceIDf format their message with fmt.Sprintf only when the record is
+enabled. Err, Dur and Component make attributes with the same keys in the records of every logger.
+
+Fatal and Fatalf log at LevelFatal, run any hooks added by RegisterShutdownHook, flush all destinations and then
+exit by calling the function set by SetExitFunc (os.Exit by default). Panic logs at LevelPanic and then panics.
 
 A custom logging level (LevelTrace) can be supplied to SetLevel to enable tracing. Tracing can
 be unconditional when calling Trace, or only enabled for pre-defined identifiers when calling TraceID. Identifiers
-for TraceID are registered by calling SetTraceIDs.
+for TraceID are registered by calling SetTraceIDs. Alternatively, the trace logger can be given its own level by
+calling Configure with a LevelSetting for Tracy, so that tracing is enabled while the normal logger is at a higher
+level such as Warn. Trace levels below LevelTrace enable more verbose traces emitted by LogTo. TraceFunc traces
+entry to and exit from a function, including its duration, returned error and any panic. Expensive trace
+arguments can be deferred until a record is actually handled with Lazy, or by calling TraceIDFunc.
 
 By default, all debug, error, info and warn messages go to Stdout, and traces go to Stderr; these destinations
 can be changed by calling RedirectNormal and RedirectTrace respectively.
 
+Records are attributed to the source line which called the logging function. Functions which wrap this package
+can call Helper, like testing.T.Helper, so that records they emit are attributed to their callers instead, or log
+through WithCallerSkip to skip a fixed number of frames.
+
 A number of settings can be changed for one or both of the normal (non-trace) and trace loggers by calling
-[Configure] - the format of log records, their destination, and whether each record contains a timestamp.
+[Configure] - the format of log records, their destination, whether each record contains a timestamp, whether
+it contains its [Source] location, the level at and above which records carry a stack trace, and a fallback
+destination to which records are written when a write to the logger's destination fails. SetErrorHandler sets a
+function which is called with the error whenever a logger fails to write a record.
+Each logger can also fan out to additional [Sink] destinations, each with its own minimum level, format and
+timestamp setting.
+
+Further loggers, such as for audit or access logs, can be added by calling RegisterLogger. Each has its own
+destination, format and level, is configured by passing its LogID to Configure, and is written to by calling LogTo.
+
+The Audit logger is an append-only trail written by AuditRecord. Its records are always JSON, are never filtered
+by level, and are chained by sequence number and SHA-256 hash so that VerifyAuditLog can detect edits and deletions.
+It has no destination until one is set by Configure, which starts a new chain, or by ResumeAudit, which continues
+the chain of an existing audit log such as the file written before the program restarted.
+
+A LogLevel is parsed from and formatted as a level name such as INFO, a name with an offset such as INFO+2, or
+an integer. Further level names, such as NOTICE or CRITICAL, can be added by calling RegisterLevel.
+
+ContextWith adds attributes, such as a request ID, to a context. DebugContext, InfoContext, WarnContext,
+ErrorContext and TraceIDContext add those attributes to the records they emit. Package loghttp provides net/http
+middleware which logs each request and gives its context a request ID, and package loggrpc provides interceptors
+which do the same for gRPC calls. Package logsql wraps a database/sql driver so that its statements are traced.
+
+Output of the standard library's log package can be sent to the normal logger by calling RedirectStdLog, and
+StdLogger returns a log.Logger which does the same. Handler returns a slog.Handler for any logger, on which the
+adapters for zap (package logzap), logrus (package loglogrus) and logr (package loglogr) are built.
 
-When used in [cli applications], a cli.Flag representing a LogLevel can be provided using the LogLevelFlag type.
+ReadMetrics returns counts of the records emitted by each logger at each level and for each trace ID, and of those
+which could not be written to their destinations. Package logexpvar publishes the same counts as an expvar variable,
+and package logprom exposes them as Prometheus metrics.
+
+Package logship provides a destination which ships records in batches to an HTTP endpoint, such as the Loki push
+API or the Elasticsearch bulk API, retrying with backoff and queueing batches on disk while the endpoint is down.
+
+When used in [cli applications], a cli.Flag representing a LogLevel can be provided using the LogLevelFlag type,
+and likewise TracesFlag, FormatFlag, OmitTimeFlag and LogDestinationFlag. CLIFlags returns a complete set of
+these flags, which the Before hook applies in one call.
 
 [cli applications]: https://github.com/urfave/cli
 */
@@ -28,20 +75,54 @@ package logger
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
-	"runtime"
 	"strings"
 	"time"
 )
 
+// handler returns the handler for a logger, fanning out to its sinks if it has any
+func handler(lc loggerConfig, trace bool) slog.Handler {
+	h := formatHandler(lc.Destination, lc.Format, trace)
+	if lc.Fallback != nil {
+		h = withFallback(h, formatHandler(lc.Fallback, lc.Format, trace))
+	}
+	id := Norm
+	if trace {
+		id = Tracy
+	}
+	return counted(withStack(fanOut(h, lc.Sinks, lc.Source, leveler(trace)), lc), id, id.String())
+}
+
+// formatHandler returns the handler which writes a logger's records to w in format f
+func formatHandler(w io.Writer, f Format, trace bool) slog.Handler {
+	if f == JSON {
+		return jsonHandler(w, trace)
+	}
+	return textHandler(w, trace)
+}
+
+// fanOut combines the handler for a logger's own destination with those of its
+// sinks. Sinks without a Level follow the owner's level
+func fanOut(h slog.Handler, sinks []Sink, src Source, owner slog.Leveler) slog.Handler {
+	if len(sinks) == 0 {
+		return h
+	}
+	handlers := []slog.Handler{h}
+	for _, s := range sinks {
+		handlers = append(handlers, sinkHandler(s, src, owner))
+	}
+	return &fanout{handlers: handlers}
+}
+
 // jsonHandler returns a JSONHandler configured per the config settings
 func jsonHandler(w io.Writer, trace bool) slog.Handler {
 	return slog.NewJSONHandler(
 		w,
 		&slog.HandlerOptions{
-			AddSource:   trace,
-			Level:       &level,
+			AddSource:   sourceConfig(trace).enabled(JSON),
+			Level:       leveler(trace),
 			ReplaceAttr: replacer(trace),
 		},
 	)
@@ -59,21 +140,54 @@ func levelAttr(a slog.Attr) slog.Attr {
 	return a
 }
 
+// leveler returns the minimum level of either the normal or the trace logger
+func leveler(trace bool) slog.Leveler {
+	if trace {
+		return &traceLevel
+	}
+	return &level
+}
+
 // replcer returns a function used as ReplaceAttr in loggers
 func replacer(trace bool) func(_ []string, a slog.Attr) slog.Attr {
 	return func(_ []string, a slog.Attr) slog.Attr {
 		a = levelAttr(a)
 		a = timeAttr(a, trace)
+		a = sourceAttr(a, sourceConfig(trace))
 		return a
 	}
 }
 
+// sinkHandler returns a handler configured per the settings of a Sink. A Sink
+// without a Level follows the level of the logger which owns it
+func sinkHandler(s Sink, src Source, owner slog.Leveler) slog.Handler {
+	if s.Level == nil {
+		s.Level = owner
+	}
+	opts := &slog.HandlerOptions{
+		AddSource: src.enabled(s.Format),
+		Level:     s.Level,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			a = levelAttr(a)
+			if a.Key == slog.TimeKey && s.OmitTime {
+				return slog.Attr{}
+			}
+			return sourceAttr(a, src)
+		},
+	}
+	if s.Format == JSON {
+		return slog.NewJSONHandler(s.Destination, opts)
+	}
+	return slog.NewTextHandler(s.Destination, opts)
+}
+
 // textHandler returns a TextNHandler configured per the config settings
 func textHandler(w io.Writer, trace bool) slog.Handler {
 	return slog.NewTextHandler(
 		w,
 		&slog.HandlerOptions{
-			Level:       &level,
+			AddSource:   sourceConfig(trace).enabled(Text),
+			Level:       leveler(trace),
 			ReplaceAttr: replacer(trace),
 		},
 	)
@@ -91,17 +205,35 @@ func timeAttr(a slog.Attr, trace bool) slog.Attr {
 
 // Debug emits a debug log
 func Debug(msg string, args ...any) {
-	slog.Debug(msg, args...)
+	emit(slog.Default().Handler(), "", slog.LevelDebug, 1, msg, args...)
+}
+
+// Debugf emits a debug log whose message is formatted by fmt.Sprintf, which is
+// only called if the log is enabled
+func Debugf(format string, args ...any) {
+	emitf(slog.Default().Handler(), "", slog.LevelDebug, 1, format, args...)
 }
 
 // Error emits an error log
 func Error(msg string, args ...any) {
-	slog.Error(msg, args...)
+	emit(slog.Default().Handler(), "", slog.LevelError, 1, msg, args...)
+}
+
+// Errorf emits an error log whose message is formatted by fmt.Sprintf, which is
+// only called if the log is enabled
+func Errorf(format string, args ...any) {
+	emitf(slog.Default().Handler(), "", slog.LevelError, 1, format, args...)
 }
 
 // Info emits an info log
 func Info(msg string, args ...any) {
-	slog.Info(msg, args...)
+	emit(slog.Default().Handler(), "", slog.LevelInfo, 1, msg, args...)
+}
+
+// Infof emits an info log whose message is formatted by fmt.Sprintf, which is
+// only called if the log is enabled
+func Infof(format string, args ...any) {
+	emitf(slog.Default().Handler(), "", slog.LevelInfo, 1, format, args...)
 }
 
 // Level returns the current logging level as a string
@@ -139,6 +271,10 @@ func SetFormat(f Format) {
 const (
 	// LevelTrace can be set to enable tracing
 	LevelTrace slog.Level = -10
+	// LevelPanic is the level of records emitted by Panic
+	LevelPanic slog.Level = 16
+	// LevelFatal is the level of records emitted by Fatal and Fatalf
+	LevelFatal slog.Level = 20
 )
 
 // SetLevel sets the default level of logging
@@ -155,26 +291,62 @@ func SetTraceIds(ids ...string) {
 
 // Trace emits one JSON-formatted log entry if trace level logging is enabled
 func Trace(msg string, args ...any) {
-	if level.Level() == LevelTrace {
-		var pcs [1]uintptr
-		runtime.Callers(2, pcs[:]) // skip [Callers, Infof]
-		r := slog.NewRecord(time.Now(), LevelTrace, msg, pcs[0])
-		r.Add(args...)
-		_ = config.traceLogger.Handler().Handle(context.Background(), r)
+	if tracing(LevelTrace) {
+		trace(1, LevelTrace, msg, args...)
+	}
+}
+
+// Tracef emits one log entry whose message is formatted by fmt.Sprintf if
+// trace level logging is enabled
+func Tracef(format string, args ...any) {
+	if tracing(LevelTrace) {
+		trace(1, LevelTrace, fmt.Sprintf(format, args...))
 	}
 }
 
 // TraceID emits one JSON-formatted log entry if tracing is enabled for the requested ID
 func TraceID(id string, msg string, args ...any) {
-	if level.Level() == LevelTrace && (config.traceIds.Contains(strings.ToLower(id)) || config.traceIds.Contains("all")) {
-		var pcs [1]uintptr
-		runtime.Callers(2, pcs[:]) // skip [Callers, Infof]
-		r := slog.NewRecord(time.Now(), LevelTrace, msg, pcs[0])
-		r.Add(args...)
-		_ = config.traceLogger.Handler().Handle(context.Background(), r)
+	if traceIDEnabled(id) {
+		countTraceID(id)
+		trace(1, LevelTrace, msg, args...)
 	}
 }
 
+// TraceIDf emits one log entry whose message is formatted by fmt.Sprintf if
+// tracing is enabled for the requested ID
+func TraceIDf(id string, format string, args ...any) {
+	if traceIDEnabled(id) {
+		countTraceID(id)
+		trace(1, LevelTrace, fmt.Sprintf(format, args...))
+	}
+}
+
+// trace writes one record to the trace logger, attributed to the caller skip
+// frames above the caller of trace
+func trace(skip int, l slog.Level, msg string, args ...any) {
+	traceContext(context.Background(), skip+1, l, msg, args...)
+}
+
+// traceContext writes one record to the trace logger, adding the attributes
+// of a context made by ContextWith
+func traceContext(ctx context.Context, skip int, l slog.Level, msg string, args ...any) {
+	r := slog.NewRecord(time.Now(), l, msg, callerPC(skip+1))
+	r.AddAttrs(contextAttrs(ctx)...)
+	r.Add(args...)
+	_ = config.traceLogger.Handler().Handle(ctx, r)
+}
+
+// TraceIDEnabled reports whether tracing is enabled for an ID, for packages which
+// trace on behalf of others
+func TraceIDEnabled(id string) bool {
+	return traceIDEnabled(id)
+}
+
+// traceIDEnabled reports whether tracing is enabled for an ID
+func traceIDEnabled(id string) bool {
+	return tracing(LevelTrace) && (config.traceIds.ContainsOne(strings.ToLower(id)) || config.traceIds.ContainsOne("all"))
+}
+
 // TraceIDs returns the list of enabled trace IDs
 func TraceIDs() []string {
 	return config.traceIds.ToSlice()
@@ -182,5 +354,11 @@ func TraceIDs() []string {
 
 // Warn emits a warning log
 func Warn(msg string, args ...any) {
-	slog.Warn(msg, args...)
+	emit(slog.Default().Handler(), "", slog.LevelWarn, 1, msg, args...)
+}
+
+// Warnf emits a warning log whose message is formatted by fmt.Sprintf, which is
+// only called if the log is enabled
+func Warnf(format string, args ...any) {
+	emitf(slog.Default().Handler(), "", slog.LevelWarn, 1, format, args...)
 }
@@ -250,6 +250,19 @@ func Test_levelAttr(t *testing.T) {
 				Value: slog.StringValue("TRACE"),
 			},
 		},
+		{
+			name: "offset-level",
+			args: args{
+				slog.Attr{
+					Key:   "level",
+					Value: slog.AnyValue(slog.LevelWarn + 1),
+				},
+			},
+			want: slog.Attr{
+				Key:   "level",
+				Value: slog.StringValue("WARN+1"),
+			},
+		},
 		{
 			name: "not-level",
 			args: args{
@@ -797,3 +810,122 @@ func TestWarn(t *testing.T) {
 		})
 	}
 }
+
+// formatted counts the number of times it is formatted
+type formatted struct {
+	calls int
+}
+
+func (f *formatted) String() string {
+	f.calls++
+	return "formatted"
+}
+
+func TestPrintf(t *testing.T) {
+	save := config
+	saveDefault := slog.Default()
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		slog.SetDefault(saveDefault)
+		level.Set(saveLevel)
+	}()
+	tests := []struct {
+		name   string
+		level  slog.Level
+		ids    set.Set[string]
+		log    func(format string, args ...any)
+		wantRe string
+	}{
+		{
+			name:   "debugf",
+			level:  slog.LevelDebug,
+			log:    Debugf,
+			wantRe: "^level=DEBUG msg=\"value formatted\"\n$",
+		},
+		{
+			name:   "debugf-below-level",
+			level:  slog.LevelInfo,
+			log:    Debugf,
+			wantRe: "^$",
+		},
+		{
+			name:   "infof",
+			level:  slog.LevelInfo,
+			log:    Infof,
+			wantRe: "^level=INFO msg=\"value formatted\"\n$",
+		},
+		{
+			name:   "warnf",
+			level:  slog.LevelInfo,
+			log:    Warnf,
+			wantRe: "^level=WARN msg=\"value formatted\"\n$",
+		},
+		{
+			name:   "errorf",
+			level:  slog.LevelInfo,
+			log:    Errorf,
+			wantRe: "^level=ERROR msg=\"value formatted\"\n$",
+		},
+		{
+			name:   "errorf-below-level",
+			level:  LevelFatal,
+			log:    Errorf,
+			wantRe: "^$",
+		},
+		{
+			name:   "tracef",
+			level:  LevelTrace,
+			log:    Tracef,
+			wantRe: "^level=TRACE msg=\"value formatted\"\n$",
+		},
+		{
+			name:   "tracef-below-level",
+			level:  slog.LevelDebug,
+			log:    Tracef,
+			wantRe: "^$",
+		},
+		{
+			name:  "traceidf",
+			level: LevelTrace,
+			ids:   set.NewSet("printf"),
+			log: func(format string, args ...any) {
+				TraceIDf("printf", format, args...)
+			},
+			wantRe: "^level=TRACE msg=\"value formatted\"\n$",
+		},
+		{
+			name:  "traceidf-disabled",
+			level: LevelTrace,
+			ids:   set.NewSet("other"),
+			log: func(format string, args ...any) {
+				TraceIDf("printf", format, args...)
+			},
+			wantRe: "^$",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			SetLevel(tt.level)
+			config.Normal.OmitTime = true
+			config.Trace.OmitTime = true
+			config.Trace.Source = Source{}
+			config.traceIds = tt.ids
+			if config.traceIds == nil {
+				config.traceIds = set.NewSet[string]()
+			}
+			slog.SetDefault(slog.New(textHandler(w, false)))
+			config.traceLogger = slog.New(textHandler(w, true))
+			f := &formatted{}
+			tt.log("value %s", f)
+			ok, err := regexp.MatchString(tt.wantRe, w.String())
+			if !ok {
+				t.Errorf("%s() got %s want %s error %v", tt.name, w.String(), tt.wantRe, err)
+			}
+			if wantCalls := min(w.Len(), 1); f.calls != wantCalls {
+				t.Errorf("%s() formatted %d times want %d", tt.name, f.calls, wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// namedLogger is a logger registered by RegisterLogger
+type namedLogger struct {
+	id     LogID
+	name   string
+	config loggerConfig
+	level  slog.LevelVar
+	logger *slog.Logger
+}
+
+var (
+	namedLock sync.RWMutex
+	named     = map[LogID]*namedLogger{}
+	names     = map[string]LogID{}
+)
+
+// configure applies a change to the settings of a named logger and
+// rebuilds its handler
+func (n *namedLogger) configure(change func(lc *loggerConfig)) {
+	namedLock.Lock()
+	defer namedLock.Unlock()
+	change(&n.config)
+	n.build()
+}
+
+// build creates the slog.Logger of a named logger from its settings
+func (n *namedLogger) build() {
+	own := Sink{
+		Destination: n.config.Destination,
+		Format:      n.config.Format,
+		Level:       &n.level,
+		OmitTime:    n.config.OmitTime,
+	}
+	h := sinkHandler(own, n.config.Source, &n.level)
+	if n.config.Fallback != nil {
+		own.Destination = n.config.Fallback
+		h = withFallback(h, sinkHandler(own, n.config.Source, &n.level))
+	}
+	h = withStack(fanOut(h, n.config.Sinks, n.config.Source, &n.level), n.config)
+	n.logger = slog.New(counted(h, n.id, n.name))
+}
+
+// handler returns the current handler of a named logger
+func (n *namedLogger) handler() slog.Handler {
+	namedLock.RLock()
+	defer namedLock.RUnlock()
+	return n.logger.Handler()
+}
+
+// lookup finds a logger registered by RegisterLogger
+func lookup(id LogID) (n *namedLogger, ok bool) {
+	namedLock.RLock()
+	defer namedLock.RUnlock()
+	n, ok = named[id]
+	return
+}
+
+// String returns the name of a logger
+func (i LogID) String() string {
+	switch i {
+	case Norm:
+		return "Norm"
+	case Tracy:
+		return "Tracy"
+	case Audit:
+		return "Audit"
+	}
+	if n, ok := lookup(i); ok {
+		return n.name
+	}
+	return "LogID(" + strconv.Itoa(int(i)) + ")"
+}
+
+// LoggerID returns the identifier of a logger registered by RegisterLogger
+func LoggerID(name string) (id LogID, ok bool) {
+	namedLock.RLock()
+	defer namedLock.RUnlock()
+	id, ok = names[strings.ToLower(name)]
+	return
+}
+
+// LogTo emits a log entry at the given level to the identified logger
+func LogTo(id LogID, l slog.Level, msg string, args ...any) {
+	logTo(1, id, l, msg, args...)
+}
+
+// logTo emits a log entry to the identified logger, attributed to the
+// caller skip frames above the caller of logTo
+func logTo(skip int, id LogID, l slog.Level, msg string, args ...any) {
+	switch id {
+	case Norm:
+		emit(slog.Default().Handler(), "", l, skip+1, msg, args...)
+	case Tracy:
+		if tracing(l) {
+			trace(skip+1, l, msg, args...)
+		}
+	case Audit:
+		auditLog.emit(l, msg, callerPC(skip+1), args...)
+	default:
+		if n, ok := lookup(id); ok {
+			emit(n.handler(), n.name, l, skip+1, msg, args...)
+		}
+	}
+}
+
+// RegisterLogger adds a logger in addition to the normal and trace loggers.
+// Its initial destination, format, level and timestamp setting are taken from
+// defaults, and may later be changed by calling Configure with the returned LogID.
+// A nil Destination defaults to Stdout, and an empty Format to Text
+func RegisterLogger(name string, defaults Sink) (LogID, error) {
+	key := strings.ToLower(name)
+	switch key {
+	case "":
+		return 0, fmt.Errorf("a logger must have a name")
+	case strings.ToLower(Norm.String()), strings.ToLower(Tracy.String()), strings.ToLower(Audit.String()):
+		return 0, fmt.Errorf("there is already a logger called %s", name)
+	}
+	switch defaults.Format {
+	case "":
+		defaults.Format = Text
+	case JSON, Text:
+	default:
+		return 0, fmt.Errorf("unknown logger Format value %v", defaults.Format)
+	}
+	if defaults.Destination == nil {
+		defaults.Destination = os.Stdout
+	}
+	n := &namedLogger{
+		name: name,
+		config: loggerConfig{
+			Destination: defaults.Destination,
+			Format:      defaults.Format,
+			OmitTime:    defaults.OmitTime,
+		},
+	}
+	if defaults.Level != nil {
+		n.level.Set(defaults.Level.Level())
+	}
+	namedLock.Lock()
+	defer namedLock.Unlock()
+	if _, exists := names[key]; exists {
+		return 0, fmt.Errorf("there is already a logger called %s", name)
+	}
+	id := firstNamed + LogID(len(named))
+	n.id = id
+	n.build()
+	named[id] = n
+	names[key] = id
+	return id, nil
+}
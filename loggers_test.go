@@ -0,0 +1,309 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"testing"
+)
+
+// resetNamed removes all loggers registered by RegisterLogger
+func resetNamed() {
+	namedLock.Lock()
+	defer namedLock.Unlock()
+	named = map[LogID]*namedLogger{}
+	names = map[string]LogID{}
+}
+
+func TestLogID_String(t *testing.T) {
+	defer resetNamed()
+	access, err := RegisterLogger("access", Sink{})
+	if err != nil {
+		t.Fatalf("RegisterLogger() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		i    LogID
+		want string
+	}{
+		{
+			name: "norm",
+			i:    Norm,
+			want: "Norm",
+		},
+		{
+			name: "tracy",
+			i:    Tracy,
+			want: "Tracy",
+		},
+		{
+			name: "audit",
+			i:    Audit,
+			want: "Audit",
+		},
+		{
+			name: "registered",
+			i:    access,
+			want: "access",
+		},
+		{
+			name: "whatthe",
+			i:    77,
+			want: "LogID(77)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.i.String(); got != tt.want {
+				t.Errorf("LogID.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterLogger(t *testing.T) {
+	defer resetNamed()
+	type args struct {
+		name     string
+		defaults Sink
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    LogID
+		wantErr bool
+	}{
+		{
+			name: "first",
+			args: args{
+				name:     "security",
+				defaults: Sink{Format: JSON},
+			},
+			want:    firstNamed,
+			wantErr: false,
+		},
+		{
+			name: "second",
+			args: args{
+				name:     "access",
+				defaults: Sink{Destination: &bytes.Buffer{}, Level: slog.LevelDebug},
+			},
+			want:    firstNamed + 1,
+			wantErr: false,
+		},
+		{
+			name: "duplicate",
+			args: args{
+				name: "Security",
+			},
+			wantErr: true,
+		},
+		{
+			name: "builtin",
+			args: args{
+				name: "norm",
+			},
+			wantErr: true,
+		},
+		{
+			name: "audit",
+			args: args{
+				name: "audit",
+			},
+			wantErr: true,
+		},
+		{
+			name: "no-name",
+			args: args{
+				name: "",
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad-format",
+			args: args{
+				name:     "metrics",
+				defaults: Sink{Format: "xml"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RegisterLogger(tt.args.name, tt.args.defaults)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RegisterLogger() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("RegisterLogger() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoggerID(t *testing.T) {
+	defer resetNamed()
+	access, err := RegisterLogger("access", Sink{})
+	if err != nil {
+		t.Fatalf("RegisterLogger() error = %v", err)
+	}
+	tests := []struct {
+		name   string
+		logger string
+		want   LogID
+		wantOk bool
+	}{
+		{
+			name:   "found",
+			logger: "ACCESS",
+			want:   access,
+			wantOk: true,
+		},
+		{
+			name:   "missing",
+			logger: "security",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LoggerID(tt.logger)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("LoggerID() = %v, %v want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestLogTo(t *testing.T) {
+	defer resetNamed()
+	save := config
+	saveDefault := slog.Default()
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		slog.SetDefault(saveDefault)
+		level.Set(saveLevel)
+	}()
+	var (
+		norm   = &bytes.Buffer{}
+		tracy  = &bytes.Buffer{}
+		access = &bytes.Buffer{}
+	)
+	id, err := RegisterLogger("access", Sink{Destination: access, Format: JSON, Level: slog.LevelWarn, OmitTime: true})
+	if err != nil {
+		t.Fatalf("RegisterLogger() error = %v", err)
+	}
+	RedirectStandard(norm)
+	RedirectTrace(tracy)
+	tests := []struct {
+		name   string
+		id     LogID
+		lev    slog.Level
+		global slog.Level
+		w      *bytes.Buffer
+		wantRe string
+	}{
+		{
+			name:   "norm",
+			id:     Norm,
+			lev:    slog.LevelWarn,
+			global: slog.LevelInfo,
+			w:      norm,
+			wantRe: `^time=.+ level=WARN msg=hello one=1\n$`,
+		},
+		{
+			name:   "norm-below-level",
+			id:     Norm,
+			lev:    slog.LevelDebug,
+			global: slog.LevelInfo,
+			w:      norm,
+			wantRe: `^$`,
+		},
+		{
+			name:   "tracy",
+			id:     Tracy,
+			lev:    LevelTrace,
+			global: LevelTrace,
+			w:      tracy,
+			wantRe: `^time=.+ level=TRACE msg=hello one=1\n$`,
+		},
+		{
+			name:   "tracy-disabled",
+			id:     Tracy,
+			lev:    LevelTrace,
+			global: slog.LevelInfo,
+			w:      tracy,
+			wantRe: `^$`,
+		},
+		{
+			name:   "named",
+			id:     id,
+			lev:    slog.LevelError,
+			global: slog.LevelInfo,
+			w:      access,
+			wantRe: `^{"level":"ERROR","msg":"hello","one":1}\n$`,
+		},
+		{
+			name:   "named-below-level",
+			id:     id,
+			lev:    slog.LevelInfo,
+			global: slog.LevelDebug,
+			w:      access,
+			wantRe: `^$`,
+		},
+		{
+			name:   "unknown",
+			id:     77,
+			lev:    slog.LevelError,
+			global: slog.LevelInfo,
+			w:      &bytes.Buffer{},
+			wantRe: `^$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.w.Reset()
+			SetLevel(tt.global)
+			LogTo(tt.id, tt.lev, "hello", "one", 1)
+			ok, err := regexp.MatchString(tt.wantRe, tt.w.String())
+			if !ok {
+				t.Errorf("LogTo() got %s want %s error %v", tt.w.String(), tt.wantRe, err)
+			}
+		})
+	}
+}
+
+func TestConfigure_named(t *testing.T) {
+	defer resetNamed()
+	var (
+		before = &bytes.Buffer{}
+		after  = &bytes.Buffer{}
+	)
+	id, err := RegisterLogger("security", Sink{Destination: before})
+	if err != nil {
+		t.Fatalf("RegisterLogger() error = %v", err)
+	}
+	err = Configure(
+		ConfigSetting{AppliesTo: id, Key: DestinationSetting, Value: after},
+		ConfigSetting{AppliesTo: id, Key: FormatSetting, Value: JSON},
+		ConfigSetting{AppliesTo: id, Key: OmitTimeSetting, Value: true},
+		ConfigSetting{AppliesTo: id, Key: LevelSetting, Value: slog.LevelDebug},
+	)
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	LogTo(id, slog.LevelDebug, "hello")
+	if before.Len() != 0 {
+		t.Errorf("Configure() old destination got %s", before.String())
+	}
+	want := `{"level":"DEBUG","msg":"hello"}` + "\n"
+	if after.String() != want {
+		t.Errorf("Configure() got %s want %s", after.String(), want)
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+/*
+Package loggrpc provides [gRPC] interceptors which log calls through package
+[github.com/bruceesmith/logger].
+
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(loggrpc.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(loggrpc.StreamServerInterceptor()),
+	)
+
+The outcome and duration of each call is written to the normal logger, or another
+chosen logger, at a level depending upon its status code: Info for success and
+for errors caused by the caller, Warn for errors which may be transient and Error
+for errors within the server.
+
+The metadata and messages of a call are traced when tracing is enabled for the
+trace ID "grpc", for the service, such as "grpc.health.v1.Health", or for the
+method, such as "grpc.health.v1.Health/Check". Credentials in metadata are
+redacted.
+
+[gRPC]: https://grpc.io
+*/
+package loggrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bruceesmith/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultTraceID is the trace ID which traces every call unless Options.TraceID is set
+const DefaultTraceID = "grpc"
+
+// redacted are the metadata keys whose values are never traced
+var redacted = []string{"authorization", "cookie", "proxy-authorization"}
+
+// Options configure the interceptors
+type Options struct {
+	Logger  logger.LogID // Logger of call outcomes; the normal logger by default
+	TraceID string       // Trace ID which traces every call; DefaultTraceID if empty
+}
+
+// UnaryServerInterceptor logs unary calls to a server with the default Options
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return Options{}.UnaryServerInterceptor()
+}
+
+// StreamServerInterceptor logs streaming calls to a server with the default Options
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return Options{}.StreamServerInterceptor()
+}
+
+// UnaryClientInterceptor logs unary calls by a client with the default Options
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return Options{}.UnaryClientInterceptor()
+}
+
+// StreamClientInterceptor logs streaming calls by a client with the default Options
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return Options{}.StreamClientInterceptor()
+}
+
+// UnaryServerInterceptor logs unary calls to a server
+func (o Options) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		id, traced := o.tracing(info.FullMethod)
+		if traced {
+			md, _ := metadata.FromIncomingContext(ctx)
+			logger.TraceIDContext(ctx, id, "grpc metadata", "method", info.FullMethod, mdAttr(md))
+			logger.TraceIDContext(ctx, id, "grpc request", "method", info.FullMethod, message(req))
+		}
+		resp, err := handler(ctx, req)
+		if traced && err == nil {
+			logger.TraceIDContext(ctx, id, "grpc response", "method", info.FullMethod, message(resp))
+		}
+		o.outcome(ctx, "grpc server call", info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor logs streaming calls to a server
+func (o Options) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := ss.Context()
+		if id, traced := o.tracing(info.FullMethod); traced {
+			md, _ := metadata.FromIncomingContext(ctx)
+			logger.TraceIDContext(ctx, id, "grpc metadata", "method", info.FullMethod, mdAttr(md))
+			ss = &serverStream{ServerStream: ss, method: info.FullMethod, id: id}
+		}
+		err := handler(srv, ss)
+		o.outcome(ctx, "grpc server call", info.FullMethod, start, err)
+		return err
+	}
+}
+
+// UnaryClientInterceptor logs unary calls by a client
+func (o Options) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		id, traced := o.tracing(method)
+		if traced {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			logger.TraceIDContext(ctx, id, "grpc metadata", "method", method, mdAttr(md))
+			logger.TraceIDContext(ctx, id, "grpc request", "method", method, message(req))
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if traced && err == nil {
+			logger.TraceIDContext(ctx, id, "grpc response", "method", method, message(reply))
+		}
+		o.outcome(ctx, "grpc client call", method, start, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor logs streaming calls by a client. The outcome of a
+// call is logged when the stream is created and, if it is created, when it ends
+func (o Options) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		id, traced := o.tracing(method)
+		if traced {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			logger.TraceIDContext(ctx, id, "grpc metadata", "method", method, mdAttr(md))
+		}
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			o.outcome(ctx, "grpc client call", method, start, err)
+			return nil, err
+		}
+		return &clientStream{ClientStream: cs, options: o, method: method, id: id, traced: traced, start: start, single: !desc.ServerStreams}, nil
+	}
+}
+
+// tracing returns the enabled trace ID, if any, of a method
+func (o Options) tracing(fullMethod string) (string, bool) {
+	method := strings.TrimPrefix(fullMethod, "/")
+	service, _, _ := strings.Cut(method, "/")
+	all := o.TraceID
+	if all == "" {
+		all = DefaultTraceID
+	}
+	for _, id := range []string{method, service, all} {
+		if logger.TraceIDEnabled(id) {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// outcome logs the result of a call
+func (o Options) outcome(ctx context.Context, msg string, method string, start time.Time, err error) {
+	code := status.Code(err)
+	args := []any{"method", method, "code", code.String(), logger.Dur(time.Since(start))}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		args = append(args, "peer", p.Addr.String())
+	}
+	if err != nil {
+		args = append(args, logger.Err(err))
+	}
+	logger.LogTo(o.Logger, level(code), msg, args...)
+}
+
+// level returns the level of the outcome of a call with a status code
+func level(code codes.Code) slog.Level {
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.Unauthenticated:
+		return slog.LevelInfo
+	case codes.DeadlineExceeded, codes.PermissionDenied, codes.ResourceExhausted, codes.FailedPrecondition,
+		codes.Aborted, codes.OutOfRange, codes.Unavailable:
+		return slog.LevelWarn
+	}
+	return slog.LevelError
+}
+
+// message returns a message as an attribute, formatted as JSON if it is a
+// protocol buffer
+func message(m any) slog.Attr {
+	if pm, ok := m.(proto.Message); ok {
+		if b, err := protojson.Marshal(pm); err == nil {
+			return slog.String("message", string(b))
+		}
+	}
+	return slog.String("message", fmt.Sprint(m))
+}
+
+// mdAttr returns metadata as a group in key order with credentials redacted
+func mdAttr(md metadata.MD) slog.Attr {
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	attrs := make([]any, 0, len(keys))
+	for _, k := range keys {
+		value := strings.Join(md[k], ", ")
+		if slices.Contains(redacted, k) {
+			value = "REDACTED"
+		}
+		attrs = append(attrs, slog.String(k, value))
+	}
+	return slog.Group("metadata", attrs...)
+}
+
+// serverStream traces the messages of a server stream
+type serverStream struct {
+	grpc.ServerStream
+	method string
+	id     string
+}
+
+// SendMsg traces a message sent by the server
+func (s *serverStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		logger.TraceIDContext(s.Context(), s.id, "grpc sent", "method", s.method, message(m))
+	}
+	return err
+}
+
+// RecvMsg traces a message received by the server
+func (s *serverStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		logger.TraceIDContext(s.Context(), s.id, "grpc received", "method", s.method, message(m))
+	}
+	return err
+}
+
+// clientStream traces the messages of a client stream and logs its outcome
+type clientStream struct {
+	grpc.ClientStream
+	options Options
+	method  string
+	id      string
+	traced  bool
+	start   time.Time
+	single  bool
+	done    bool
+}
+
+// SendMsg traces a message sent by the client
+func (c *clientStream) SendMsg(m any) error {
+	err := c.ClientStream.SendMsg(m)
+	if err == nil && c.traced {
+		logger.TraceIDContext(c.Context(), c.id, "grpc sent", "method", c.method, message(m))
+	}
+	return err
+}
+
+// RecvMsg traces a message received by the client, and logs the outcome of
+// the call when the stream ends. A call whose server sends a single message
+// ends when it is received, because gRPC does not return io.EOF after it
+func (c *clientStream) RecvMsg(m any) error {
+	err := c.ClientStream.RecvMsg(m)
+	if err == nil && c.traced {
+		logger.TraceIDContext(c.Context(), c.id, "grpc received", "method", c.method, message(m))
+	}
+	if c.done || (err == nil && !c.single) {
+		return err
+	}
+	c.done = true
+	outcome := err
+	if errors.Is(err, io.EOF) {
+		outcome = nil
+	}
+	c.options.outcome(c.Context(), "grpc client call", c.method, c.start, outcome)
+	return err
+}
@@ -0,0 +1,220 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package loggrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bruceesmith/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// syncBuffer is a bytes.Buffer shared by the client and server
+type syncBuffer struct {
+	lock sync.Mutex
+	b    bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuffer) lines() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.b.Len() == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s.b.String(), "\n"), "\n")
+}
+
+func (s *syncBuffer) reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.b.Reset()
+}
+
+// inputServer is a TestService which counts the messages of a client stream
+type inputServer struct {
+	testpb.UnimplementedTestServiceServer
+}
+
+func (inputServer) StreamingInputCall(stream grpc.ClientStreamingServer[testpb.StreamingInputCallRequest, testpb.StreamingInputCallResponse]) error {
+	var n int32
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&testpb.StreamingInputCallResponse{AggregatedPayloadSize: n})
+		}
+		if err != nil {
+			return err
+		}
+		n++
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	defer func() {
+		logger.SetLevel(slog.LevelInfo)
+		_ = logger.Configure(
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: os.Stdout},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: false},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: os.Stderr},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.OmitTimeSetting, Value: false},
+		)
+	}()
+	w := &syncBuffer{}
+	_ = logger.Configure(
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: true},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.OmitTimeSetting, Value: true},
+	)
+	logger.SetLevel(logger.LevelTrace)
+	logger.SetTraceIds("grpc.health.v1.Health/Watch", "grpc.test")
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor()),
+		grpc.StreamInterceptor(StreamServerInterceptor()),
+	)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	testpb.RegisterTestServiceServer(server, inputServer{})
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(Options{TraceID: "grpc.test"}.StreamClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+	testClient := testpb.NewTestServiceClient(conn)
+
+	tests := []struct {
+		name   string
+		call   func(ctx context.Context)
+		wantRe []string
+	}{
+		{
+			name: "unary",
+			call: func(ctx context.Context) {
+				_, _ = client.Check(ctx, &healthpb.HealthCheckRequest{})
+			},
+			wantRe: []string{
+				`^level=INFO msg="grpc server call" method=/grpc.health.v1.Health/Check code=OK duration=\S+ peer=bufconn$`,
+				`^level=INFO msg="grpc client call" method=/grpc.health.v1.Health/Check code=OK duration=\S+$`,
+			},
+		},
+		{
+			name: "unary-error",
+			call: func(ctx context.Context) {
+				_, _ = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "missing"})
+			},
+			wantRe: []string{
+				`^level=INFO msg="grpc server call" method=/grpc.health.v1.Health/Check code=NotFound duration=\S+ peer=bufconn error=".+unknown service"$`,
+				`^level=INFO msg="grpc client call" method=/grpc.health.v1.Health/Check code=NotFound duration=\S+ error=".+unknown service"$`,
+			},
+		},
+		{
+			name: "stream-traced",
+			call: func(ctx context.Context) {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret", "tenant", "acme")
+				ctx, cancel := context.WithCancel(ctx)
+				stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+				if err != nil {
+					t.Fatalf("Watch() error = %v", err)
+				}
+				_, _ = stream.Recv()
+				cancel()
+				_, _ = stream.Recv()
+			},
+			wantRe: []string{
+				`^level=TRACE msg="grpc metadata" method=/grpc.health.v1.Health/Watch metadata.authorization=REDACTED metadata.tenant=acme$`,
+				`^level=TRACE msg="grpc metadata" method=/grpc.health.v1.Health/Watch metadata.:authority=bufnet metadata.authorization=REDACTED metadata.content-type=application/grpc metadata.tenant=acme metadata.user-agent=.+$`,
+				`^level=TRACE msg="grpc sent" method=/grpc.health.v1.Health/Watch message={}$`,
+				`^level=TRACE msg="grpc received" method=/grpc.health.v1.Health/Watch message={}$`,
+				`^level=TRACE msg="grpc sent" method=/grpc.health.v1.Health/Watch message="{\\"status\\":\s*\\"SERVING\\"}"$`,
+				`^level=TRACE msg="grpc received" method=/grpc.health.v1.Health/Watch message="{\\"status\\":\s*\\"SERVING\\"}"$`,
+				`^level=INFO msg="grpc client call" method=/grpc.health.v1.Health/Watch code=Canceled duration=\S+ (peer=bufconn )?error=".+"$`,
+				`^level=INFO msg="grpc server call" method=/grpc.health.v1.Health/Watch code=Canceled duration=\S+ peer=bufconn error=".+"$`,
+			},
+		},
+		{
+			name: "client-stream",
+			call: func(ctx context.Context) {
+				stream, err := testClient.StreamingInputCall(ctx)
+				if err != nil {
+					t.Fatalf("StreamingInputCall() error = %v", err)
+				}
+				for range 2 {
+					_ = stream.Send(&testpb.StreamingInputCallRequest{})
+				}
+				if _, err := stream.CloseAndRecv(); err != nil {
+					t.Fatalf("CloseAndRecv() error = %v", err)
+				}
+			},
+			wantRe: []string{
+				`^level=TRACE msg="grpc metadata" method=/grpc.testing.TestService/StreamingInputCall$`,
+				`^level=TRACE msg="grpc sent" method=/grpc.testing.TestService/StreamingInputCall message={}$`,
+				`^level=TRACE msg="grpc sent" method=/grpc.testing.TestService/StreamingInputCall message={}$`,
+				`^level=TRACE msg="grpc received" method=/grpc.testing.TestService/StreamingInputCall message="{\\"aggregatedPayloadSize\\":\s*2}"$`,
+				`^level=INFO msg="grpc client call" method=/grpc.testing.TestService/StreamingInputCall code=OK duration=\S+( peer=bufconn)?$`,
+				`^level=INFO msg="grpc server call" method=/grpc.testing.TestService/StreamingInputCall code=OK duration=\S+ peer=bufconn$`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w.reset()
+			tt.call(context.Background())
+			var lines []string
+			for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+				if lines = w.lines(); len(lines) >= len(tt.wantRe) {
+					break
+				}
+			}
+			if len(lines) != len(tt.wantRe) {
+				t.Fatalf("interceptors got %d records want %d: %s", len(lines), len(tt.wantRe), strings.Join(lines, "\n"))
+			}
+			for _, re := range tt.wantRe {
+				found := false
+				for _, line := range lines {
+					if ok, _ := regexp.MatchString(re, line); ok {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("interceptors got %s want a record matching %s", strings.Join(lines, "\n"), re)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+/*
+Package loghttp provides [net/http] middleware which logs each request through
+package [github.com/bruceesmith/logger].
+
+	http.ListenAndServe(":8080", loghttp.Middleware(mux))
+
+Each request is given a request ID, taken from its X-Request-ID header or else
+generated, which is returned in the response's header and added to the request's
+context by logger.ContextWith. Records emitted by handlers with that context,
+such as by logger.InfoContext, then carry the request ID.
+
+When the request completes, an access record of its method, path, status, bytes
+written, duration and remote address is written to the normal logger or another
+chosen logger. When tracing is enabled for the trace ID "http.server", the
+request and response headers are also traced, with credentials redacted.
+
+Transport is an http.RoundTripper for clients, which traces each request under
+the trace ID "http.client" with the time taken by each phase of the request.
+
+	client := &http.Client{Transport: &loghttp.Transport{BodyLimit: 1024}}
+*/
+package loghttp
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bruceesmith/logger"
+)
+
+const (
+	// DefaultTraceID is the trace ID of header traces unless Options.TraceID is set
+	DefaultTraceID = "http.server"
+	// DefaultRequestIDHeader is the header of request IDs unless Options.RequestIDHeader is set
+	DefaultRequestIDHeader = "X-Request-ID"
+	// RequestIDKey is the key of the request ID attribute
+	RequestIDKey = "request_id"
+)
+
+// redacted are the headers whose values are never traced
+var redacted = []string{"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie"}
+
+// requestIDKey is the context key of a request ID
+type requestIDKey struct{}
+
+// Options configure the Middleware
+type Options struct {
+	Logger          logger.LogID // Logger of access records; the normal logger by default
+	Level           slog.Level   // Level of access records; Info by default
+	TraceID         string       // Trace ID of header traces; DefaultTraceID if empty
+	RequestIDHeader string       // Header holding the request ID; DefaultRequestIDHeader if empty
+}
+
+// Middleware logs requests to next with the default Options
+func Middleware(next http.Handler) http.Handler {
+	return Options{}.Middleware(next)
+}
+
+// Middleware logs requests to next
+func (o Options) Middleware(next http.Handler) http.Handler {
+	if o.TraceID == "" {
+		o.TraceID = DefaultTraceID
+	}
+	if o.RequestIDHeader == "" {
+		o.RequestIDHeader = DefaultRequestIDHeader
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(o.RequestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		w.Header().Set(o.RequestIDHeader, id)
+		ctx := withRequestID(r, id)
+		r = r.WithContext(ctx)
+		if logger.TraceIDEnabled(o.TraceID) {
+			logger.TraceIDContext(ctx, o.TraceID, "request headers", headers(r.Header))
+		}
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+		if logger.TraceIDEnabled(o.TraceID) {
+			logger.TraceIDContext(ctx, o.TraceID, "response headers", headers(w.Header()))
+		}
+		logger.LogTo(o.Logger, o.Level, "request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rw.status,
+			"bytes", rw.bytes,
+			logger.Dur(time.Since(start)),
+			"remote", r.RemoteAddr,
+			RequestIDKey, id,
+		)
+	})
+}
+
+// RequestID returns the request ID of a request's context
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// withRequestID returns the context of a request with its request ID
+func withRequestID(r *http.Request, id string) context.Context {
+	ctx := logger.ContextWith(r.Context(), RequestIDKey, id)
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// newRequestID generates a random request ID
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// headers returns a group of headers in name order with credentials redacted
+func headers(h http.Header) slog.Attr {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	attrs := make([]any, 0, len(names))
+	for _, name := range names {
+		value := strings.Join(h[name], ", ")
+		if slices.Contains(redacted, http.CanonicalHeaderKey(name)) {
+			value = "REDACTED"
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.Group("headers", attrs...)
+}
+
+// responseWriter records the status and size of a response
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+// WriteHeader records the status of the response
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the number of bytes written
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Unwrap returns the underlying ResponseWriter, for http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush sends buffered data to the client if the underlying ResponseWriter can
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack takes over the connection, such as for a WebSocket, if the underlying
+// ResponseWriter can. A hijacked response is logged with the status 101
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("cannot hijack the connection: %w", http.ErrNotSupported)
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && !w.wroteHeader {
+		w.status = http.StatusSwitchingProtocols
+		w.wroteHeader = true
+	}
+	return conn, rw, err
+}
@@ -0,0 +1,187 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package loghttp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bruceesmith/logger"
+)
+
+func TestMiddleware(t *testing.T) {
+	defer func() {
+		logger.SetLevel(slog.LevelInfo)
+		_ = logger.Configure(
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: os.Stdout},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: false},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: os.Stderr},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.OmitTimeSetting, Value: false},
+		)
+	}()
+	w := &bytes.Buffer{}
+	_ = logger.Configure(
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: true},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.OmitTimeSetting, Value: true},
+	)
+	logger.SetTraceIds("http.test")
+	var gotID string
+	hello := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = RequestID(r.Context())
+		logger.InfoContext(r.Context(), "handling")
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	})
+	tests := []struct {
+		name    string
+		options Options
+		level   slog.Level
+		header  map[string]string
+		wantID  string
+		wantRe  []string
+	}{
+		{
+			name:   "default",
+			level:  slog.LevelInfo,
+			header: map[string]string{"X-Request-ID": "abc"},
+			wantID: "abc",
+			wantRe: []string{
+				`^level=INFO msg=handling request_id=abc$`,
+				`^level=INFO msg=request method=GET path=/hello status=201 bytes=5 duration=\S+ remote=192.0.2.1:1234 request_id=abc$`,
+			},
+		},
+		{
+			name:    "traced",
+			options: Options{TraceID: "http.test", Level: slog.LevelDebug, RequestIDHeader: "X-Trace"},
+			level:   logger.LevelTrace,
+			header:  map[string]string{"X-Trace": "t1", "Authorization": "Bearer secret", "Accept": "text/plain"},
+			wantID:  "t1",
+			wantRe: []string{
+				`^level=TRACE msg="request headers" request_id=t1 headers.Accept=text/plain headers.Authorization=REDACTED headers.X-Trace=t1$`,
+				`^level=INFO msg=handling request_id=t1$`,
+				`^level=TRACE msg="response headers" request_id=t1 headers.Content-Type=text/plain headers.X-Trace=t1$`,
+				`^level=DEBUG msg=request method=GET path=/hello status=201 bytes=5 duration=\S+ remote=192.0.2.1:1234 request_id=t1$`,
+			},
+		},
+		{
+			name:    "generated-id",
+			options: Options{TraceID: "http.other"},
+			level:   logger.LevelTrace,
+			wantRe: []string{
+				`^level=INFO msg=handling request_id=[0-9a-f]{32}$`,
+				`^level=INFO msg=request method=GET path=/hello status=201 bytes=5 duration=\S+ remote=192.0.2.1:1234 request_id=[0-9a-f]{32}$`,
+			},
+		},
+		{
+			name:    "below-level",
+			options: Options{Level: slog.LevelDebug},
+			level:   slog.LevelInfo,
+			wantRe: []string{
+				`^level=INFO msg=handling request_id=[0-9a-f]{32}$`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w.Reset()
+			logger.SetLevel(tt.level)
+			req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			tt.options.Middleware(hello).ServeHTTP(rec, req)
+			header := tt.options.RequestIDHeader
+			if header == "" {
+				header = DefaultRequestIDHeader
+			}
+			if rec.Header().Get(header) != gotID || (tt.wantID != "" && gotID != tt.wantID) {
+				t.Errorf("Middleware() request ID got %s and %s want %s", gotID, rec.Header().Get(header), tt.wantID)
+			}
+			lines := strings.Split(strings.TrimSuffix(w.String(), "\n"), "\n")
+			if len(lines) != len(tt.wantRe) {
+				t.Fatalf("Middleware() got %d records want %d: %s", len(lines), len(tt.wantRe), w.String())
+			}
+			for i := range lines {
+				ok, err := regexp.MatchString(tt.wantRe[i], lines[i])
+				if !ok {
+					t.Errorf("Middleware() got %s want %s error %v", lines[i], tt.wantRe[i], err)
+				}
+			}
+		})
+	}
+}
+
+func TestMiddleware_hijack(t *testing.T) {
+	defer func() {
+		_ = logger.Configure(
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: os.Stdout},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: false},
+		)
+	}()
+	w := &bytes.Buffer{}
+	_ = logger.Configure(
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: true},
+	)
+	done := make(chan struct{})
+	upgrade := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, ok := w.(http.Hijacker)
+		if !ok {
+			t.Errorf("Middleware() ResponseWriter is not an http.Hijacker")
+			return
+		}
+		conn, rw, err := h.Hijack()
+		if err != nil {
+			t.Errorf("Hijack() error %v", err)
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\nhello")
+		_ = rw.Flush()
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		Middleware(upgrade).ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("net.Dial() error %v", err)
+	}
+	defer conn.Close()
+	_, _ = conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: test\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\n"))
+	resp, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatalf("io.ReadAll() error %v", err)
+	}
+	if !strings.HasPrefix(string(resp), "HTTP/1.1 101 ") || !strings.HasSuffix(string(resp), "hello") {
+		t.Errorf("Hijack() got response %q want an upgrade", resp)
+	}
+	<-done
+	want := `^level=INFO msg=request method=GET path=/ws status=101 bytes=0 duration=\S+ remote=\S+ request_id=[0-9a-f]{32}\n$`
+	if ok, err := regexp.MatchString(want, w.String()); !ok {
+		t.Errorf("Middleware() got %s want %s error %v", w.String(), want, err)
+	}
+}
+
+func TestResponseWriter_Hijack(t *testing.T) {
+	rw := &responseWriter{ResponseWriter: httptest.NewRecorder()}
+	if _, _, err := rw.Hijack(); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("responseWriter.Hijack() got %v want %v", err, http.ErrNotSupported)
+	}
+}
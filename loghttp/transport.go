@@ -0,0 +1,139 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package loghttp
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/bruceesmith/logger"
+)
+
+// DefaultClientTraceID is the trace ID of a Transport unless its TraceID is set
+const DefaultClientTraceID = "http.client"
+
+// Transport is an http.RoundTripper which traces each request made through it,
+// when tracing is enabled for its trace ID. A trace records the method, the URL
+// without any user credentials, the status and the duration of the request,
+// with the time taken by DNS lookup, connection, TLS handshake and the wait for
+// the first byte of the response. When tracing is not enabled, requests are
+// passed to Base without any other work. Tracing bodies delays each request
+// until BodyLimit bytes of its body have been read
+type Transport struct {
+	Base      http.RoundTripper // Transport which makes requests; http.DefaultTransport if nil
+	TraceID   string            // Trace ID of the traces; DefaultClientTraceID if empty
+	BodyLimit int               // Maximum bytes of the request and response bodies traced; none if 0
+}
+
+// timings are the times taken by the phases of a request
+type timings struct {
+	lock                             sync.Mutex
+	dnsStart, connectStart, tlsStart time.Time
+	dns, connect, tls, firstByte     time.Duration
+	start                            time.Time
+}
+
+// trace returns an httptrace.ClientTrace which records timings
+func (t *timings) trace() *httptrace.ClientTrace {
+	since := func(start time.Time, d *time.Duration) {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		*d = time.Since(start)
+	}
+	mark := func(start *time.Time) {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		*start = time.Now()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { mark(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { since(t.dnsStart, &t.dns) },
+		ConnectStart:         func(string, string) { mark(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { since(t.connectStart, &t.connect) },
+		TLSHandshakeStart:    func() { mark(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { since(t.tlsStart, &t.tls) },
+		GotFirstResponseByte: func() { since(t.start, &t.firstByte) },
+	}
+}
+
+// attr returns the timings that were recorded as a group
+func (t *timings) attr() slog.Attr {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var attrs []any
+	for _, d := range []struct {
+		key string
+		d   time.Duration
+	}{
+		{"dns", t.dns},
+		{"connect", t.connect},
+		{"tls", t.tls},
+		{"first_byte", t.firstByte},
+	} {
+		if d.d > 0 {
+			attrs = append(attrs, slog.Duration(d.key, d.d))
+		}
+	}
+	return slog.Group("timing", attrs...)
+}
+
+// RoundTrip makes a request through Base, tracing it if tracing is enabled
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	id := t.TraceID
+	if id == "" {
+		id = DefaultClientTraceID
+	}
+	if !logger.TraceIDEnabled(id) {
+		return base.RoundTrip(req)
+	}
+	tm := &timings{start: time.Now()}
+	ctx := req.Context()
+	req = req.Clone(httptrace.WithClientTrace(ctx, tm.trace()))
+	u := *req.URL
+	u.User = nil
+	args := []any{"method", req.Method, "url", u.String()}
+	if t.BodyLimit > 0 && req.Body != nil && req.Body != http.NoBody {
+		var body []byte
+		body, req.Body = peek(req.Body, t.BodyLimit)
+		args = append(args, "request_body", string(body))
+	}
+	resp, err := base.RoundTrip(req)
+	args = append(args, logger.Dur(time.Since(tm.start)), tm.attr())
+	if err != nil {
+		logger.TraceIDContext(ctx, id, "http request failed", append(args, logger.Err(err))...)
+		return resp, err
+	}
+	args = append(args, "status", resp.StatusCode)
+	if t.BodyLimit > 0 && resp.Body != nil && resp.Body != http.NoBody {
+		var body []byte
+		body, resp.Body = peek(resp.Body, t.BodyLimit)
+		args = append(args, "response_body", string(body))
+	}
+	logger.TraceIDContext(ctx, id, "http request", args...)
+	return resp, nil
+}
+
+// peekBody is a body whose first bytes have been read by peek
+type peekBody struct {
+	io.Reader
+	io.Closer
+}
+
+// peek reads up to limit bytes from a body, returning them and a body which
+// reads the whole of the original
+func peek(body io.ReadCloser, limit int) ([]byte, io.ReadCloser) {
+	b, _ := io.ReadAll(io.LimitReader(body, int64(limit)))
+	return b, peekBody{Reader: io.MultiReader(bytes.NewReader(b), body), Closer: body}
+}
@@ -0,0 +1,100 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package loghttp
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bruceesmith/logger"
+)
+
+func TestTransport(t *testing.T) {
+	defer func() {
+		logger.SetLevel(slog.LevelInfo)
+		_ = logger.Configure(
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: os.Stderr},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.OmitTimeSetting, Value: false},
+		)
+	}()
+	w := &bytes.Buffer{}
+	_ = logger.Configure(
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.OmitTimeSetting, Value: true},
+	)
+	logger.SetTraceIds("http.client.test")
+	logger.SetLevel(logger.LevelTrace)
+	echo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = io.Copy(w, r.Body)
+	}))
+	defer echo.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	credentials := func(url string) string {
+		return strings.Replace(url, "http://", "http://user:secret@", 1)
+	}
+	tests := []struct {
+		name      string
+		transport *Transport
+		url       string
+		wantErr   bool
+		wantRe    string
+	}{
+		{
+			name:      "traced",
+			transport: &Transport{TraceID: "http.client.test"},
+			url:       credentials(echo.URL) + "/echo?q=1",
+			wantRe:    `^level=TRACE msg="http request" method=POST url="http://127.0.0.1:\d+/echo\?q=1" duration=\S+ timing.connect=\S+ timing.first_byte=\S+ status=202` + "\n$",
+		},
+		{
+			name:      "bodies",
+			transport: &Transport{TraceID: "http.client.test", BodyLimit: 5},
+			url:       echo.URL,
+			wantRe:    `^level=TRACE msg="http request" method=POST url=http://127.0.0.1:\d+ request_body=hello duration=\S+ .*status=202 response_body=hello` + "\n$",
+		},
+		{
+			name:      "disabled",
+			transport: &Transport{BodyLimit: 5},
+			url:       echo.URL,
+			wantRe:    "^$",
+		},
+		{
+			name:      "failed",
+			transport: &Transport{TraceID: "http.client.test"},
+			url:       closed.URL,
+			wantErr:   true,
+			wantRe:    `^level=TRACE msg="http request failed" method=POST url=http://127.0.0.1:\d+ duration=\S+ .*error=".*refused"` + "\n$",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w.Reset()
+			client := &http.Client{Transport: tt.transport}
+			resp, err := client.Post(tt.url, "text/plain", strings.NewReader("hello, world"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RoundTrip() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				body, _ := io.ReadAll(resp.Body)
+				_ = resp.Body.Close()
+				if string(body) != "hello, world" {
+					t.Errorf("RoundTrip() response body got %s want hello, world", body)
+				}
+			}
+			ok, err := regexp.MatchString(tt.wantRe, w.String())
+			if !ok {
+				t.Errorf("RoundTrip() got %s want %s error %v", w.String(), tt.wantRe, err)
+			}
+		})
+	}
+}
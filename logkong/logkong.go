@@ -0,0 +1,130 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+/*
+Package logkong provides the options of package [github.com/bruceesmith/logger] as
+flags of a [Kong] command line. Embedding LoggingFlags in the command-line struct
+adds the flags, and applies them once Kong has parsed the command line.
+
+	var cli struct {
+		logkong.LoggingFlags `embed:""`
+		...
+	}
+	kong.Parse(&cli)
+
+[Kong]: https://github.com/alecthomas/kong
+*/
+package logkong
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/alecthomas/kong"
+	"github.com/bruceesmith/logger"
+)
+
+// LogLevel is a logger.LogLevel which implements kong.MapperValue
+type LogLevel logger.LogLevel
+
+// Decode implements kong.MapperValue
+func (l *LogLevel) Decode(ctx *kong.DecodeContext) error {
+	var s string
+	if err := ctx.Scan.PopValueInto("level", &s); err != nil {
+		return err
+	}
+	var ll logger.LogLevel
+	if err := ll.Set(s); err != nil {
+		return err
+	}
+	*l = LogLevel(ll)
+	return nil
+}
+
+// Format is a logger.Format which implements kong.MapperValue. It may be
+// empty, which leaves the format unchanged
+type Format logger.Format
+
+// Decode implements kong.MapperValue
+func (f *Format) Decode(ctx *kong.DecodeContext) error {
+	var s string
+	if err := ctx.Scan.PopValueInto("format", &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*f = ""
+		return nil
+	}
+	return (*logger.Format)(f).UnmarshalText([]byte(s))
+}
+
+// Traces is a logger.Traces which implements kong.MapperValue. The flag can
+// be repeated, and each value can be a comma-separated list
+type Traces logger.Traces
+
+// Decode implements kong.MapperValue
+func (t *Traces) Decode(ctx *kong.DecodeContext) error {
+	var s string
+	if err := ctx.Scan.PopValueInto("trace IDs", &s); err != nil {
+		return err
+	}
+	return (*logger.Traces)(t).Set(s)
+}
+
+// LoggingFlags are the logging flags of a Kong command line. Each flag can
+// also be set by an environment variable. Flags which are not set leave the
+// current settings unchanged
+type LoggingFlags struct {
+	LogLevel    LogLevel `name:"log-level" env:"LOG_LEVEL" help:"Level of logging."`
+	LogFormat   Format   `name:"log-format" enum:",text,json" default:"" env:"LOG_FORMAT" help:"Format of log entries (text or json)."`
+	TraceFormat Format   `name:"trace-format" enum:",text,json" default:"" env:"TRACE_FORMAT" help:"Format of trace entries (text or json)."`
+	TraceIDs    Traces   `name:"trace-ids" env:"TRACE_IDS" help:"Comma-separated trace IDs to enable."`
+	OmitTime    bool     `name:"log-omit-time" env:"LOG_OMIT_TIME" help:"Omit timestamps from log entries."`
+	LogFile     string   `name:"log-file" env:"LOG_FILE" help:"Destination of log entries (stdout, stderr or a file)."`
+	TraceFile   string   `name:"trace-file" env:"TRACE_FILE" help:"Destination of trace entries (stdout, stderr or a file)."`
+}
+
+// AfterApply implements Kong's AfterApply hook, applying the flags which were
+// set by calling logger.Configure, logger.SetLevel and logger.SetTraceIds.
+// As in logger.Options, empty formats and destinations are not applied
+func (f *LoggingFlags) AfterApply(ctx *kong.Context) error {
+	set := make(map[string]bool)
+	for _, flag := range ctx.Flags() {
+		set[flag.Name] = flag.Set
+	}
+	var settings []logger.ConfigSetting
+	if f.LogFormat != "" {
+		settings = append(settings, logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.FormatSetting, Value: logger.Format(f.LogFormat)})
+	}
+	if f.TraceFormat != "" {
+		settings = append(settings, logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.FormatSetting, Value: logger.Format(f.TraceFormat)})
+	}
+	if set["log-omit-time"] {
+		settings = append(settings, logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: f.OmitTime})
+	}
+	for _, d := range []struct {
+		log  logger.LogID
+		name string
+	}{
+		{log: logger.Norm, name: f.LogFile},
+		{log: logger.Tracy, name: f.TraceFile},
+	} {
+		if d.name == "" {
+			continue
+		}
+		w, err := logger.OpenDestination(d.name)
+		if err != nil {
+			return fmt.Errorf("cannot apply logging flags: %w", err)
+		}
+		settings = append(settings, logger.ConfigSetting{AppliesTo: d.log, Key: logger.DestinationSetting, Value: w})
+	}
+	if err := logger.Configure(settings...); err != nil {
+		return fmt.Errorf("cannot apply logging flags: %w", err)
+	}
+	if set["log-level"] {
+		logger.SetLevel(slog.Level(f.LogLevel))
+	}
+	logger.SetTraceIds(f.TraceIDs...)
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logkong
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/bruceesmith/logger"
+)
+
+func TestLoggingFlags(t *testing.T) {
+	defer func() {
+		logger.SetLevel(slog.LevelInfo)
+		_ = logger.Configure(
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: os.Stdout},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.FormatSetting, Value: logger.Text},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: false},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: os.Stderr},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.FormatSetting, Value: logger.Text},
+		)
+	}()
+	file := filepath.Join(t.TempDir(), "log")
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		want    LoggingFlags
+		wantLog bool
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			args: []string{},
+			want: LoggingFlags{
+				LogLevel: LogLevel(slog.LevelInfo),
+			},
+		},
+		{
+			name: "all",
+			args: []string{
+				"--log-level", "info+2",
+				"--log-format", "json",
+				"--trace-format", "json",
+				"--trace-ids", "db,http",
+				"--trace-ids", "grpc",
+				"--log-omit-time",
+				"--log-file", file,
+				"--trace-file", "stdout",
+			},
+			want: LoggingFlags{
+				LogLevel:    LogLevel(slog.LevelInfo + 2),
+				LogFormat:   Format(logger.JSON),
+				TraceFormat: Format(logger.JSON),
+				TraceIDs:    Traces{"db", "http", "grpc"},
+				OmitTime:    true,
+				LogFile:     file,
+				TraceFile:   "stdout",
+			},
+			wantLog: true,
+		},
+		{
+			name: "env",
+			env: map[string]string{
+				"LOG_LEVEL": "debug",
+				"TRACE_IDS": "db",
+			},
+			want: LoggingFlags{
+				LogLevel: LogLevel(slog.LevelDebug),
+				TraceIDs: Traces{"db"},
+			},
+		},
+		{
+			name:    "bad-level",
+			args:    []string{"--log-level", "loud"},
+			wantErr: true,
+		},
+		{
+			name:    "bad-format",
+			args:    []string{"--log-format", "xml"},
+			wantErr: true,
+		},
+		{
+			name:    "bad-file",
+			args:    []string{"--log-file", filepath.Join(file, "log")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			var cli struct {
+				LoggingFlags `embed:""`
+			}
+			parser, err := kong.New(&cli, kong.Writers(io.Discard, io.Discard), kong.Exit(func(int) {}))
+			if err != nil {
+				t.Fatalf("kong.New() error = %v", err)
+			}
+			_, err = parser.Parse(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := cli.LoggingFlags
+			if got.LogLevel != tt.want.LogLevel || got.LogFormat != tt.want.LogFormat || got.TraceFormat != tt.want.TraceFormat ||
+				!slices.Equal(got.TraceIDs, tt.want.TraceIDs) || got.OmitTime != tt.want.OmitTime ||
+				got.LogFile != tt.want.LogFile || got.TraceFile != tt.want.TraceFile {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+			ll := logger.LogLevel(tt.want.LogLevel)
+			if level := logger.Level(); level != ll.String() {
+				t.Errorf("AfterApply() level = %v, want %v", level, ll.String())
+			}
+			if tt.wantLog {
+				logger.Warn("hello")
+				content, err := os.ReadFile(file)
+				if err != nil {
+					t.Fatalf("ReadFile() error = %v", err)
+				}
+				if ok, _ := regexp.Match(`^{"level":"WARN","msg":"hello"}\n$`, content); !ok {
+					t.Errorf("AfterApply() log file contains %s", content)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggingFlags_unset(t *testing.T) {
+	defer func() {
+		logger.SetLevel(slog.LevelInfo)
+		_ = logger.Configure(
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: os.Stdout},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.FormatSetting, Value: logger.Text},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: false},
+		)
+	}()
+	w := &bytes.Buffer{}
+	err := logger.Configure(
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.FormatSetting, Value: logger.JSON},
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: true},
+	)
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	logger.SetLevel(slog.LevelWarn)
+	var cli struct {
+		LoggingFlags `embed:""`
+	}
+	parser, err := kong.New(&cli, kong.Writers(io.Discard, io.Discard), kong.Exit(func(int) {}))
+	if err != nil {
+		t.Fatalf("kong.New() error = %v", err)
+	}
+	if _, err := parser.Parse(nil); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if level := logger.Level(); level != "WARN" {
+		t.Errorf("AfterApply() level = %v, want WARN", level)
+	}
+	logger.Info("hidden")
+	logger.Warn("kept")
+	if got, want := w.String(), `{"level":"WARN","msg":"kept"}`+"\n"; got != want {
+		t.Errorf("AfterApply() destination got %s want %s", got, want)
+	}
+}
@@ -5,9 +5,14 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode"
 
 	"github.com/urfave/cli/v3"
 )
@@ -15,40 +20,100 @@ import (
 // LogLevel is the level of logging
 type LogLevel int
 
-// String is a convenience method for pflag.Value
+// levelName is a level with a name, such as INFO
+type levelName struct {
+	name  string
+	level slog.Level
+}
+
+var (
+	levelLock sync.RWMutex
+	// levelNames are the named levels in ascending order of level
+	levelNames = []levelName{
+		{name: "TRACE", level: LevelTrace},
+		{name: "DEBUG", level: slog.LevelDebug},
+		{name: "INFO", level: slog.LevelInfo},
+		{name: "WARN", level: slog.LevelWarn},
+		{name: "ERROR", level: slog.LevelError},
+		{name: "PANIC", level: LevelPanic},
+		{name: "FATAL", level: LevelFatal},
+	}
+)
+
+// RegisterLevel adds a named level such as NOTICE or CRITICAL, which is then
+// accepted by LogLevel.Set and shown by name in log records. Names are letters
+// only and are case-insensitive, and each level can have only one name
+func RegisterLevel(name string, l slog.Level) error {
+	name = strings.ToUpper(name)
+	if name == "" || strings.IndexFunc(name, func(r rune) bool { return !unicode.IsLetter(r) }) >= 0 {
+		return fmt.Errorf("invalid level name %q", name)
+	}
+	levelLock.Lock()
+	defer levelLock.Unlock()
+	i := 0
+	for ; i < len(levelNames) && levelNames[i].level <= l; i++ {
+		if levelNames[i].level == l {
+			return fmt.Errorf("level %d is already named %s", l, levelNames[i].name)
+		}
+	}
+	for _, ln := range levelNames {
+		if ln.name == name {
+			return fmt.Errorf("there is already a level called %s", name)
+		}
+	}
+	levelNames = slices.Insert(levelNames, i, levelName{name: name, level: l})
+	return nil
+}
+
+// String is a convenience method for pflag.Value. Levels without a name are
+// shown relative to the nearest named level below them, for example INFO+2
 func (ll *LogLevel) String() (s string) {
-	switch *ll {
-	case LogLevel(slog.LevelInfo):
-		s = "INFO"
-	case LogLevel(slog.LevelError):
-		s = "ERROR"
-	case LogLevel(slog.LevelWarn):
-		s = "WARN"
-	case LogLevel(slog.LevelDebug):
-		s = "DEBUG"
-	case LogLevel(LevelTrace):
-		s = "TRACE"
+	l := slog.Level(*ll)
+	levelLock.RLock()
+	defer levelLock.RUnlock()
+	base := levelNames[0]
+	for _, ln := range levelNames {
+		if ln.level > l {
+			break
+		}
+		base = ln
+	}
+	switch {
+	case l == base.level:
+		s = base.name
+	case l > base.level:
+		s = base.name + "+" + strconv.Itoa(int(l-base.level))
+	default:
+		s = base.name + strconv.Itoa(int(l-base.level))
 	}
 	return
 }
 
-// Set is a convenience method for pflag.Value
+// Set is a convenience method for pflag.Value. It accepts a level name such as
+// info, a name with an offset such as INFO+2 or debug-3, or an integer
 func (ll *LogLevel) Set(ls string) (err error) {
-	switch strings.ToLower(ls) {
-	case "info":
-		*ll = LogLevel(slog.LevelInfo)
-	case "error":
-		*ll = LogLevel(slog.LevelError)
-	case "warn":
-		*ll = LogLevel(slog.LevelWarn)
-	case "debug":
-		*ll = LogLevel(slog.LevelDebug)
-	case "trace":
-		*ll = LogLevel(LevelTrace)
-	default:
-		err = fmt.Errorf("invalid log level %v", ls)
+	ls = strings.TrimSpace(ls)
+	if n, convErr := strconv.Atoi(ls); convErr == nil {
+		*ll = LogLevel(n)
+		return
 	}
-	return
+	name, offset := ls, 0
+	if i := strings.IndexAny(ls, "+-"); i > 0 {
+		name = ls[:i]
+		offset, err = strconv.Atoi(ls[i:])
+		if err != nil {
+			return fmt.Errorf("invalid log level %v", ls)
+		}
+	}
+	levelLock.RLock()
+	defer levelLock.RUnlock()
+	for _, ln := range levelNames {
+		if strings.EqualFold(ln.name, name) {
+			*ll = LogLevel(ln.level) + LogLevel(offset)
+			return
+		}
+	}
+	return fmt.Errorf("invalid log level %v", ls)
 }
 
 // Type is a conveniene method for pflag.Value
@@ -56,15 +121,57 @@ func (ll *LogLevel) Type() string {
 	return "LogLevel"
 }
 
-// UnmarshalJSON is a convenience method for Kong
+// LogValue implements slog.LogValuer
+func (ll LogLevel) LogValue() slog.Value {
+	return slog.StringValue(ll.String())
+}
+
+// MarshalJSON implements json.Marshaler
+func (ll LogLevel) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ll.String())
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (ll LogLevel) MarshalText() ([]byte, error) {
+	return []byte(ll.String()), nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (ll LogLevel) MarshalYAML() (any, error) {
+	return ll.String(), nil
+}
+
+// UnmarshalJSON is a convenience method for Kong. It accepts a JSON
+// string or number, or an unquoted level name
 func (ll *LogLevel) UnmarshalJSON(jason []byte) (err error) {
-	err = ll.Set(string(jason))
+	s := string(jason)
+	if len(jason) > 0 && jason[0] == '"' {
+		err = json.Unmarshal(jason, &s)
+		if err != nil {
+			return fmt.Errorf("cannot unmarshal %s, %w", string(jason), err)
+		}
+	}
+	err = ll.Set(s)
 	if err != nil {
 		return fmt.Errorf("cannot unmarshal %s, %w", string(jason), err)
 	}
 	return
 }
 
+// UnmarshalText implements encoding.TextUnmarshaler
+func (ll *LogLevel) UnmarshalText(text []byte) error {
+	return ll.Set(string(text))
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (ll *LogLevel) UnmarshalYAML(unmarshal func(any) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return ll.Set(s)
+}
+
 // LogLevelFlag is useful for using a LogLevel as a command-line flag in CLI applications
 type LogLevelFlag = cli.FlagBase[LogLevel, cli.NoConfig, logLevelValue]
 
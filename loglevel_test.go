@@ -7,6 +7,7 @@ package logger
 import (
 	"log/slog"
 	"reflect"
+	"slices"
 	"testing"
 
 	"github.com/urfave/cli/v3"
@@ -44,9 +45,43 @@ func TestLogLevel_String(t *testing.T) {
 			ll:    LogLevel(LevelTrace),
 			wantS: "TRACE",
 		},
+		{
+			name:  "above",
+			ll:    LogLevel(slog.LevelInfo + 2),
+			wantS: "INFO+2",
+		},
+		{
+			name:  "above-highest",
+			ll:    LogLevel(slog.LevelError + 4),
+			wantS: "ERROR+4",
+		},
+		{
+			name:  "fatal",
+			ll:    LogLevel(LevelFatal),
+			wantS: "FATAL",
+		},
+		{
+			name:  "above-fatal",
+			ll:    LogLevel(LevelFatal + 1),
+			wantS: "FATAL+1",
+		},
+		{
+			name:  "below-lowest",
+			ll:    LogLevel(LevelTrace - 3),
+			wantS: "TRACE-3",
+		},
+		{
+			name:  "registered",
+			ll:    LogLevel(slog.LevelInfo + 2),
+			wantS: "NOTICE",
+		},
 	}
+	defer restoreLevelNames(levelNames)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			if tt.name == "registered" {
+				_ = RegisterLevel("notice", slog.LevelInfo+2)
+			}
 			logl = tt.ll
 			if gotS := logl.String(); gotS != tt.wantS {
 				t.Errorf("LogLevel.String() = %v, want %v", gotS, tt.wantS)
@@ -114,6 +149,14 @@ func TestLogLevel_Set(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "offset-fail",
+			ll:   &logl,
+			args: args{
+				ls: "info+two",
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -124,6 +167,154 @@ func TestLogLevel_Set(t *testing.T) {
 	}
 }
 
+func TestLogLevel_Set_value(t *testing.T) {
+	defer restoreLevelNames(levelNames)
+	if err := RegisterLevel("Critical", slog.LevelError+4); err != nil {
+		t.Fatalf("RegisterLevel() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		ls   string
+		want LogLevel
+	}{
+		{
+			name: "name",
+			ls:   "Warn",
+			want: LogLevel(slog.LevelWarn),
+		},
+		{
+			name: "plus",
+			ls:   "INFO+2",
+			want: LogLevel(slog.LevelInfo + 2),
+		},
+		{
+			name: "minus",
+			ls:   "debug-3",
+			want: LogLevel(slog.LevelDebug - 3),
+		},
+		{
+			name: "integer",
+			ls:   "-6",
+			want: LogLevel(-6),
+		},
+		{
+			name: "registered",
+			ls:   "critical",
+			want: LogLevel(slog.LevelError + 4),
+		},
+		{
+			name: "registered-offset",
+			ls:   " CRITICAL+1 ",
+			want: LogLevel(slog.LevelError + 5),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ll LogLevel
+			if err := ll.Set(tt.ls); err != nil || ll != tt.want {
+				t.Errorf("LogLevel.Set() = %v, error = %v, want %v", ll, err, tt.want)
+			}
+		})
+	}
+}
+
+// restoreLevelNames undoes calls to RegisterLevel
+func restoreLevelNames(saved []levelName) {
+	levelLock.Lock()
+	defer levelLock.Unlock()
+	levelNames = slices.Clone(saved)
+}
+
+func TestRegisterLevel(t *testing.T) {
+	defer restoreLevelNames(levelNames)
+	type args struct {
+		name string
+		l    slog.Level
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "notice",
+			args: args{
+				name: "notice",
+				l:    slog.LevelInfo + 2,
+			},
+			wantErr: false,
+		},
+		{
+			name: "critical",
+			args: args{
+				name: "CRITICAL",
+				l:    slog.LevelError + 4,
+			},
+			wantErr: false,
+		},
+		{
+			name: "builtin",
+			args: args{
+				name: "fatal",
+				l:    LevelFatal + 4,
+			},
+			wantErr: true,
+		},
+		{
+			name: "lowest",
+			args: args{
+				name: "Verbose",
+				l:    LevelTrace - 2,
+			},
+			wantErr: false,
+		},
+		{
+			name: "duplicate-name",
+			args: args{
+				name: "Notice",
+				l:    slog.LevelInfo + 1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate-level",
+			args: args{
+				name: "Important",
+				l:    slog.LevelWarn,
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad-name",
+			args: args{
+				name: "INFO2",
+				l:    slog.LevelInfo + 3,
+			},
+			wantErr: true,
+		},
+		{
+			name: "no-name",
+			args: args{
+				name: "",
+				l:    slog.LevelInfo + 3,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RegisterLevel(tt.args.name, tt.args.l); (err != nil) != tt.wantErr {
+				t.Errorf("RegisterLevel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	for i := 1; i < len(levelNames); i++ {
+		if levelNames[i-1].level >= levelNames[i].level {
+			t.Errorf("RegisterLevel() levels out of order %v", levelNames)
+		}
+	}
+}
+
 func TestLogLevel_Type(t *testing.T) {
 	var logl LogLevel
 	tests := []struct {
@@ -165,6 +356,22 @@ func TestLogLevel_UnmarshalJSON(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "quoted",
+			ll:   &ll,
+			args: args{
+				jason: []byte(`"debug"`),
+			},
+			wantErr: false,
+		},
+		{
+			name: "number",
+			ll:   &ll,
+			args: args{
+				jason: []byte("-4"),
+			},
+			wantErr: false,
+		},
 		{
 			name: "error",
 			ll:   &ll,
@@ -173,6 +380,14 @@ func TestLogLevel_UnmarshalJSON(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "bad-quoted",
+			ll:   &ll,
+			args: args{
+				jason: []byte(`"debug`),
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
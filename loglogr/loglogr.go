@@ -0,0 +1,167 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+/*
+Package loglogr provides a [logr] LogSink which writes to the loggers of package
+[github.com/bruceesmith/logger], so that libraries which log with logr obey its
+levels, destinations and trace IDs.
+
+	log := loglogr.New(logger.Norm, 2)
+
+V(0) records are written at Info level, and records up to a given verbosity at
+Debug level. More verbose records are traces, written to the trace logger at
+LevelTrace and below, so that V(3) is LevelTrace and V(4) is LevelTrace-1 when
+the Debug verbosity is 2.
+
+The names given by WithName are joined with dots, such as "controller.pods", and
+added to records as their component. Each name is also a trace ID: traces are
+only written when tracing is enabled by SetTraceIds for the name or for one of
+the names it extends, such as "controller". Traces from a Logger with no name
+require the "all" trace ID.
+
+[logr]: https://github.com/go-logr/logr
+*/
+package loglogr
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/bruceesmith/logger"
+	"github.com/go-logr/logr"
+)
+
+// sink is a logr.LogSink which writes to a logger and the trace logger
+type sink struct {
+	handler  slog.Handler
+	trace    slog.Handler
+	maxDebug int
+	name     string
+	depth    int
+}
+
+// New returns a logr.Logger which writes to the identified logger, where
+// maxDebug is the most verbose V level written at Debug level
+func New(id logger.LogID, maxDebug int) logr.Logger {
+	return logr.New(NewLogSink(id, maxDebug))
+}
+
+// NewLogSink returns a logr.LogSink which writes to the identified logger,
+// where maxDebug is the most verbose V level written at Debug level
+func NewLogSink(id logger.LogID, maxDebug int) logr.LogSink {
+	return &sink{
+		handler:  logger.Handler(id),
+		trace:    logger.Handler(logger.Tracy),
+		maxDebug: max(maxDebug, 0),
+	}
+}
+
+// level converts a logr verbosity to a slog level
+func (s *sink) level(v int) slog.Level {
+	switch {
+	case v <= 0:
+		return slog.LevelInfo
+	case v <= s.maxDebug:
+		return slog.LevelDebug
+	}
+	return logger.LevelTrace - slog.Level(v-s.maxDebug-1)
+}
+
+// traced reports whether a verbosity is a trace
+func (s *sink) traced(v int) bool {
+	return v > s.maxDebug
+}
+
+// tracing reports whether tracing is enabled for the name of the sink or any
+// of the names it extends
+func (s *sink) tracing() bool {
+	for name := s.name; ; {
+		if logger.TraceIDEnabled(name) {
+			return true
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return false
+		}
+		name = name[:i]
+	}
+}
+
+// Init records the number of logr frames between the caller and the sink
+func (s *sink) Init(info logr.RuntimeInfo) {
+	s.depth = info.CallDepth
+}
+
+// Enabled reports whether records at a verbosity are written
+func (s *sink) Enabled(v int) bool {
+	if s.traced(v) {
+		return s.trace.Enabled(context.Background(), s.level(v)) && s.tracing()
+	}
+	return s.handler.Enabled(context.Background(), s.level(v))
+}
+
+// Info writes a record at a verbosity
+func (s *sink) Info(v int, msg string, kv ...any) {
+	h := s.handler
+	if s.traced(v) {
+		h = s.trace
+	}
+	s.write(h, s.level(v), msg, nil, kv)
+}
+
+// Error writes a record at Error level
+func (s *sink) Error(err error, msg string, kv ...any) {
+	s.write(s.handler, slog.LevelError, msg, err, kv)
+}
+
+// write writes one record, attributed to the caller of the logr.Logger
+func (s *sink) write(h slog.Handler, l slog.Level, msg string, err error, kv []any) {
+	var pcs [1]uintptr
+	runtime.Callers(s.depth+3, pcs[:]) // skip [Callers, write, Info/Error]; s.depth skips logr
+	r := slog.NewRecord(time.Now(), l, msg, pcs[0])
+	if s.name != "" {
+		r.AddAttrs(logger.Component(s.name))
+	}
+	if err != nil {
+		r.AddAttrs(logger.Err(err))
+	}
+	r.Add(kv...)
+	_ = h.Handle(context.Background(), r)
+}
+
+// WithValues returns a sink which adds key-value pairs to each record
+func (s *sink) WithValues(kv ...any) logr.LogSink {
+	r := slog.Record{}
+	r.Add(kv...)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	c := *s
+	c.handler = s.handler.WithAttrs(attrs)
+	c.trace = s.trace.WithAttrs(attrs)
+	return &c
+}
+
+// WithName returns a sink whose name has an additional element
+func (s *sink) WithName(name string) logr.LogSink {
+	c := *s
+	if c.name != "" {
+		c.name += "."
+	}
+	c.name += name
+	return &c
+}
+
+// WithCallDepth returns a sink which attributes records to a caller further
+// up the stack
+func (s *sink) WithCallDepth(depth int) logr.LogSink {
+	c := *s
+	c.depth += depth
+	return &c
+}
@@ -0,0 +1,138 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package loglogr
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bruceesmith/logger"
+	"github.com/go-logr/logr"
+)
+
+// line returns the source line of the caller of line
+func line() int {
+	_, _, l, _ := runtime.Caller(1)
+	return l
+}
+
+// logVia logs on behalf of its caller
+func logVia(l logr.Logger, msg string) {
+	l.WithCallDepth(1).Info(msg)
+}
+
+func TestNew(t *testing.T) {
+	defer func() {
+		logger.SetLevel(slog.LevelInfo)
+		_ = logger.Configure(
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: os.Stdout},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: false},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.SourceSetting, Value: false},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: os.Stderr},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.OmitTimeSetting, Value: false},
+		)
+	}()
+	w := &bytes.Buffer{}
+	_ = logger.Configure(
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: true},
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.SourceSetting, Value: logger.Source{Text: true, Paths: logger.ShortPaths}},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.OmitTimeSetting, Value: true},
+	)
+	logger.SetTraceIds("controller")
+	log := New(logger.Norm, 2)
+	tests := []struct {
+		name   string
+		level  slog.Level
+		log    func() int
+		wantRe string
+	}{
+		{
+			name:   "info",
+			level:  slog.LevelInfo,
+			log:    func() int { log.WithValues("pod", "web-1").Info("started", "port", 80); return line() },
+			wantRe: `^level=INFO source=loglogr_test.go:LINE msg=started pod=web-1 port=80` + "\n$",
+		},
+		{
+			name:   "call-depth",
+			level:  slog.LevelInfo,
+			log:    func() int { logVia(log, "via"); return line() },
+			wantRe: `^level=INFO source=loglogr_test.go:LINE msg=via` + "\n$",
+		},
+		{
+			name:   "verbose",
+			level:  slog.LevelDebug,
+			log:    func() int { log.WithName("cache").WithName("lru").V(2).Info("evicted"); return line() },
+			wantRe: `^level=DEBUG source=loglogr_test.go:LINE msg=evicted component=cache.lru` + "\n$",
+		},
+		{
+			name:   "verbose-below-level",
+			level:  slog.LevelInfo,
+			log:    func() int { log.V(1).Info("hidden"); return line() },
+			wantRe: "^$",
+		},
+		{
+			name:   "trace",
+			level:  logger.LevelTrace,
+			log:    func() int { log.WithName("controller").V(3).Info("reconciled", "pods", 2); return line() },
+			wantRe: "^level=TRACE msg=reconciled component=controller pods=2\n$",
+		},
+		{
+			name:   "trace-extended-name",
+			level:  logger.LevelTrace - 1,
+			log:    func() int { log.WithName("controller").WithName("pods").V(4).Info("listed"); return line() },
+			wantRe: "^level=TRACE-1 msg=listed component=controller.pods\n$",
+		},
+		{
+			name:   "trace-too-verbose",
+			level:  logger.LevelTrace,
+			log:    func() int { log.WithName("controller").V(4).Info("listed"); return line() },
+			wantRe: "^$",
+		},
+		{
+			name:   "trace-other-name",
+			level:  logger.LevelTrace,
+			log:    func() int { log.WithName("scheduler").V(3).Info("scheduled"); return line() },
+			wantRe: "^$",
+		},
+		{
+			name:   "trace-no-name",
+			level:  logger.LevelTrace,
+			log:    func() int { log.V(3).Info("anonymous"); return line() },
+			wantRe: "^$",
+		},
+		{
+			name:   "trace-disabled",
+			level:  slog.LevelDebug,
+			log:    func() int { log.WithName("controller").V(3).Info("reconciled"); return line() },
+			wantRe: "^$",
+		},
+		{
+			name:   "error",
+			level:  slog.LevelInfo,
+			log:    func() int { log.Error(errors.New("refused"), "failed"); return line() },
+			wantRe: `^level=ERROR source=loglogr_test.go:LINE msg=failed error=refused` + "\n$",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w.Reset()
+			logger.SetLevel(tt.level)
+			want := strings.ReplaceAll(tt.wantRe, "LINE", strconv.Itoa(tt.log()))
+			ok, err := regexp.MatchString(want, w.String())
+			if !ok {
+				t.Errorf("New() got %s want %s error %v", w.String(), want, err)
+			}
+		})
+	}
+}
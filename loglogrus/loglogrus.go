@@ -0,0 +1,104 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+/*
+Package loglogrus provides a [logrus] hook which writes to the loggers of package
+[github.com/bruceesmith/logger], so that libraries which log with logrus obey its
+levels, destinations and trace IDs. Redirect sends all of the entries of a
+logrus Logger to the hook instead of the Logger's own output.
+
+	loglogrus.Redirect(logrus.StandardLogger(), logger.Norm)
+
+Entries at logrus's TraceLevel are written to the trace logger.
+
+[logrus]: https://github.com/sirupsen/logrus
+*/
+package loglogrus
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/bruceesmith/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// Hook is a logrus.Hook which writes entries to a logger and the trace logger
+type Hook struct {
+	normal slog.Handler
+	trace  slog.Handler
+}
+
+// NewHook returns a Hook which writes to the identified logger
+func NewHook(id logger.LogID) *Hook {
+	return &Hook{
+		normal: logger.Handler(id),
+		trace:  logger.Handler(logger.Tracy),
+	}
+}
+
+// Redirect adds a Hook to a logrus Logger and discards the Logger's own
+// output. The Logger's level is set to TraceLevel, so that the Hook decides
+// which entries are written
+func Redirect(l *logrus.Logger, id logger.LogID) {
+	l.AddHook(NewHook(id))
+	l.SetOutput(io.Discard)
+	l.SetLevel(logrus.TraceLevel)
+}
+
+// level converts a logrus level to a slog level
+func level(l logrus.Level) slog.Level {
+	switch l {
+	case logrus.PanicLevel:
+		return logger.LevelPanic
+	case logrus.FatalLevel:
+		return logger.LevelFatal
+	case logrus.ErrorLevel:
+		return slog.LevelError
+	case logrus.WarnLevel:
+		return slog.LevelWarn
+	case logrus.InfoLevel:
+		return slog.LevelInfo
+	case logrus.DebugLevel:
+		return slog.LevelDebug
+	}
+	return logger.LevelTrace
+}
+
+// Levels returns all of the logrus levels
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire writes an entry if its level is enabled
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	handler := h.normal
+	if entry.Level == logrus.TraceLevel {
+		handler = h.trace
+	}
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	l := level(entry.Level)
+	if !handler.Enabled(ctx, l) {
+		return nil
+	}
+	var pc uintptr
+	if entry.Caller != nil {
+		pc = entry.Caller.PC
+	}
+	r := slog.NewRecord(entry.Time, l, entry.Message, pc)
+	for _, k := range slices.Sorted(maps.Keys(entry.Data)) {
+		if err, ok := entry.Data[k].(error); ok && k == logrus.ErrorKey {
+			r.AddAttrs(logger.Err(err))
+			continue
+		}
+		r.AddAttrs(slog.Any(k, entry.Data[k]))
+	}
+	return handler.Handle(ctx, r)
+}
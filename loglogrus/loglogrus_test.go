@@ -0,0 +1,88 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package loglogrus
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/bruceesmith/logger"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRedirect(t *testing.T) {
+	defer func() {
+		logger.SetLevel(slog.LevelInfo)
+		_ = logger.Configure(
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: os.Stdout},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.FormatSetting, Value: logger.Text},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: false},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: os.Stderr},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.FormatSetting, Value: logger.Text},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.OmitTimeSetting, Value: false},
+		)
+	}()
+	w := &bytes.Buffer{}
+	_ = logger.Configure(
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: true},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.OmitTimeSetting, Value: true},
+	)
+	l := logrus.New()
+	Redirect(l, logger.Norm)
+	tests := []struct {
+		name   string
+		level  slog.Level
+		log    func()
+		wantRe string
+	}{
+		{
+			name:   "info",
+			level:  slog.LevelInfo,
+			log:    func() { l.WithFields(logrus.Fields{"port": 80, "host": "db"}).Info("connected") },
+			wantRe: "^level=INFO msg=connected host=db port=80\n$",
+		},
+		{
+			name:   "error",
+			level:  slog.LevelInfo,
+			log:    func() { l.WithError(errors.New("refused")).Error("failed") },
+			wantRe: "^level=ERROR msg=failed error=refused\n$",
+		},
+		{
+			name:   "debug-below-level",
+			level:  slog.LevelInfo,
+			log:    func() { l.Debug("hidden") },
+			wantRe: "^$",
+		},
+		{
+			name:   "trace",
+			level:  logger.LevelTrace,
+			log:    func() { l.Trace("traced") },
+			wantRe: "^level=TRACE msg=traced\n$",
+		},
+		{
+			name:   "trace-disabled",
+			level:  slog.LevelDebug,
+			log:    func() { l.Trace("traced") },
+			wantRe: "^$",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w.Reset()
+			logger.SetLevel(tt.level)
+			tt.log()
+			ok, err := regexp.MatchString(tt.wantRe, w.String())
+			if !ok {
+				t.Errorf("Redirect() got %s want %s error %v", w.String(), tt.wantRe, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+/*
+Package logpflag registers the options of package [github.com/bruceesmith/logger] as flags
+of a [pflag.FlagSet], such as those of [cobra] commands.
+
+	opts := logpflag.BindPFlags(cmd.PersistentFlags())
+	cmd.PersistentPreRunE = func(*cobra.Command, []string) error {
+		return opts.Apply()
+	}
+
+[cobra]: https://github.com/spf13/cobra
+*/
+package logpflag
+
+import (
+	"log/slog"
+
+	"github.com/bruceesmith/logger"
+	"github.com/spf13/pflag"
+)
+
+// BindPFlags registers the logging flags --log-level, --log-format, --trace-format,
+// --trace-ids, --log-omit-time and --log-file with a FlagSet. After the FlagSet has
+// been parsed, calling Apply on the returned Options pushes the flag values into
+// package logger
+func BindPFlags(fs *pflag.FlagSet) *logger.Options {
+	o := &logger.Options{
+		Level: logger.LogLevel(slog.LevelInfo),
+	}
+	fs.Var(&o.Level, logger.LogLevelFlagName, "level of logging")
+	fs.Var(&o.Format, logger.LogFormatFlagName, "format of log entries (text or json)")
+	fs.Var(&o.TraceFormat, logger.TraceFormatFlagName, "format of trace entries (text or json)")
+	fs.Var(&o.TraceIDs, logger.TraceIDsFlagName, "comma-separated trace IDs to enable")
+	fs.BoolVar(&o.OmitTime, logger.OmitTimeFlagName, false, "omit timestamps from log entries")
+	fs.StringVar(&o.LogFile, logger.LogFileFlagName, "", "destination of log entries (stdout, stderr or a file)")
+	return o
+}
@@ -0,0 +1,94 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+/*
+Package logprom exposes the counts of records emitted by the loggers of package
+[github.com/bruceesmith/logger] as Prometheus metrics.
+
+	prometheus.MustRegister(logprom.NewCollector())
+
+The Collector reports these counters:
+
+	logger_records_total{logger, level}    records emitted by each logger at each level
+	logger_trace_records_total{trace_id}   records emitted for each trace ID
+	logger_write_failures_total{logger}    records which could not be written to at least one destination
+	logger_dropped_records_total{logger}   records which could not be written to any destination
+
+The same counts can be published as an expvar variable by package logexpvar.
+*/
+package logprom
+
+import (
+	"github.com/bruceesmith/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultNamespace is the prefix of metric names unless another is given to NewCollectorWithNamespace
+const DefaultNamespace = "logger"
+
+// Collector is a prometheus.Collector for the counts of records emitted by the loggers
+type Collector struct {
+	records  *prometheus.Desc
+	traceIDs *prometheus.Desc
+	failed   *prometheus.Desc
+	dropped  *prometheus.Desc
+}
+
+// NewCollector returns a Collector whose metric names begin with DefaultNamespace
+func NewCollector() *Collector {
+	return NewCollectorWithNamespace(DefaultNamespace)
+}
+
+// NewCollectorWithNamespace returns a Collector whose metric names begin with namespace
+func NewCollectorWithNamespace(namespace string) *Collector {
+	return &Collector{
+		records: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "records_total"),
+			"Records emitted, by logger and level",
+			[]string{"logger", "level"}, nil,
+		),
+		traceIDs: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "trace_records_total"),
+			"Records emitted for trace IDs, by trace ID",
+			[]string{"trace_id"}, nil,
+		),
+		failed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "write_failures_total"),
+			"Records which could not be written to at least one destination, by logger",
+			[]string{"logger"}, nil,
+		),
+		dropped: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "dropped_records_total"),
+			"Records which could not be written to any destination, by logger",
+			[]string{"logger"}, nil,
+		),
+	}
+}
+
+// Describe sends the descriptions of the Collector's metrics
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.records
+	ch <- c.traceIDs
+	ch <- c.failed
+	ch <- c.dropped
+}
+
+// Collect sends the current counts of records
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	m := logger.ReadMetrics()
+	for name, levels := range m.Records {
+		for level, n := range levels {
+			ch <- prometheus.MustNewConstMetric(c.records, prometheus.CounterValue, float64(n), name, level)
+		}
+	}
+	for id, n := range m.TraceIDs {
+		ch <- prometheus.MustNewConstMetric(c.traceIDs, prometheus.CounterValue, float64(n), id)
+	}
+	for name, n := range m.Failed {
+		ch <- prometheus.MustNewConstMetric(c.failed, prometheus.CounterValue, float64(n), name)
+	}
+	for name, n := range m.Dropped {
+		ch <- prometheus.MustNewConstMetric(c.dropped, prometheus.CounterValue, float64(n), name)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logprom
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bruceesmith/logger"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// failingWriter is an io.Writer whose writes always fail
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestCollector(t *testing.T) {
+	defer func() {
+		logger.SetLevel(slog.LevelInfo)
+		_ = logger.Configure(
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: os.Stdout},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: os.Stderr},
+		)
+	}()
+	w := &bytes.Buffer{}
+	_ = logger.Configure(
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: w},
+	)
+	logger.SetLevel(logger.LevelTrace)
+	logger.SetTraceIds("prom.test")
+	logger.Info("one")
+	logger.Info("two")
+	logger.Error("three")
+	logger.TraceID("prom.test", "four")
+	_ = logger.Configure(
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: failingWriter{}},
+	)
+	logger.Warn("five")
+	tests := []struct {
+		name      string
+		namespace string
+		metrics   []string
+		want      string
+	}{
+		{
+			name:    "records",
+			metrics: []string{"logger_records_total"},
+			want: `# HELP logger_records_total Records emitted, by logger and level
+# TYPE logger_records_total counter
+logger_records_total{level="ERROR",logger="Norm"} 1
+logger_records_total{level="INFO",logger="Norm"} 2
+logger_records_total{level="TRACE",logger="Tracy"} 1
+logger_records_total{level="WARN",logger="Norm"} 1
+`,
+		},
+		{
+			name:    "trace ids",
+			metrics: []string{"logger_trace_records_total"},
+			want: `# HELP logger_trace_records_total Records emitted for trace IDs, by trace ID
+# TYPE logger_trace_records_total counter
+logger_trace_records_total{trace_id="prom.test"} 1
+`,
+		},
+		{
+			name:    "failures",
+			metrics: []string{"logger_write_failures_total", "logger_dropped_records_total"},
+			want: `# HELP logger_dropped_records_total Records which could not be written to any destination, by logger
+# TYPE logger_dropped_records_total counter
+logger_dropped_records_total{logger="Norm"} 1
+# HELP logger_write_failures_total Records which could not be written to at least one destination, by logger
+# TYPE logger_write_failures_total counter
+logger_write_failures_total{logger="Norm"} 1
+`,
+		},
+		{
+			name:      "namespace",
+			namespace: "app",
+			metrics:   []string{"app_trace_records_total"},
+			want: `# HELP app_trace_records_total Records emitted for trace IDs, by trace ID
+# TYPE app_trace_records_total counter
+app_trace_records_total{trace_id="prom.test"} 1
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCollector()
+			if tt.namespace != "" {
+				c = NewCollectorWithNamespace(tt.namespace)
+			}
+			if err := testutil.CollectAndCompare(c, strings.NewReader(tt.want), tt.metrics...); err != nil {
+				t.Errorf("Collector.Collect() error %v", err)
+			}
+		})
+	}
+}
+
+func TestCollector_lint(t *testing.T) {
+	problems, err := testutil.CollectAndLint(NewCollector())
+	if err != nil {
+		t.Fatalf("testutil.CollectAndLint() error %v", err)
+	}
+	for _, p := range problems {
+		t.Errorf("testutil.CollectAndLint() got %s: %s", p.Metric, p.Text)
+	}
+}
@@ -0,0 +1,736 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+/*
+Package logship provides a destination for package [github.com/bruceesmith/logger]
+which ships records to an HTTP endpoint in batches.
+
+	s, err := logship.New(logship.Options{
+		URL:      "http://loki:3100/loki/api/v1/push",
+		Encoding: logship.Loki,
+		Labels:   map[string]string{"job": "api"},
+		SpillDir: "/var/spool/api",
+	})
+	defer s.Close()
+	logger.Configure(
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.SinksSetting, Value: []logger.Sink{{Destination: s, Format: logger.JSON}}},
+	)
+
+A Shipper is an io.Writer which collects the records written to it into a batch.
+A batch is sent when it holds MaxRecords records or MaxBytes bytes, or when it
+is FlushInterval old. Batches are compressed with gzip and POSTed in the Loki
+push format, the Elasticsearch bulk format or as newline-delimited JSON. Records
+should be written in JSON format for Loki and Elasticsearch.
+
+A batch which cannot be sent is retried with exponential backoff. When the
+retries are exhausted the batch is written to a spill queue of files in
+SpillDir, and later batches are added to the queue until the endpoint recovers.
+The queue is sent, oldest batch first, once the endpoint accepts requests again,
+including after the program restarts. Without a SpillDir, batches which cannot
+be sent are dropped. The Elasticsearch bulk API reports the outcome of each
+record, and only the records which it could not accept are retried or spilled;
+those which it rejects outright are dropped. Errors which cause records to be
+spilled or dropped are passed to OnError, which may be called concurrently.
+
+Each request is abandoned after Timeout. Flush sends any records collected so
+far, and is called by logger.Fatal; once one batch fails, Flush spills or drops
+the rest rather than waiting on the endpoint. Close flushes the Shipper and
+stops it, cancelling a request in progress and making it once more.
+*/
+package logship
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Encoding is the format of the requests which ship batches of records
+type Encoding int
+
+const (
+	NDJSON        Encoding = iota // One record per line
+	Loki                          // The Loki push API
+	Elasticsearch                 // The Elasticsearch bulk API
+)
+
+const (
+	DefaultMaxRecords    = 1000                   // Records in a batch unless Options.MaxRecords is set
+	DefaultMaxBytes      = 1 << 20                // Bytes in a batch unless Options.MaxBytes is set
+	DefaultFlushInterval = time.Second            // Age of a batch when it is sent unless Options.FlushInterval is set
+	DefaultMinBackoff    = 100 * time.Millisecond // First delay between retries unless Options.MinBackoff is set
+	DefaultMaxBackoff    = 30 * time.Second       // Longest delay between retries unless Options.MaxBackoff is set
+	DefaultMaxRetries    = 5                      // Attempts to send a batch unless Options.MaxRetries is set
+	DefaultTimeout       = 10 * time.Second       // Time limit of a request unless Options.Timeout is set
+)
+
+// spillSuffix is the file name suffix of each batch in the spill queue
+const spillSuffix = ".batch"
+
+// queueDepth is the number of full batches which can wait to be sent before
+// further batches are spilled
+const queueDepth = 8
+
+// ErrClosed is returned by a Shipper which has been closed
+var ErrClosed = errors.New("the shipper is closed")
+
+// Options configure a Shipper
+type Options struct {
+	URL           string            // Endpoint to which batches are POSTed
+	Encoding      Encoding          // Format of the requests
+	Labels        map[string]string // Labels of the Loki stream; required for Loki
+	Index         string            // Elasticsearch index or data stream; required for Elasticsearch
+	Header        http.Header       // Additional request headers, such as Authorization
+	Client        *http.Client      // Client which sends requests; http.DefaultClient if nil
+	MaxRecords    int               // Records in a full batch; DefaultMaxRecords if 0
+	MaxBytes      int               // Bytes of records in a full batch; DefaultMaxBytes if 0
+	FlushInterval time.Duration     // Interval at which partial batches are sent; DefaultFlushInterval if 0
+	MinBackoff    time.Duration     // First delay between retries; DefaultMinBackoff if 0
+	MaxBackoff    time.Duration     // Longest delay between retries; DefaultMaxBackoff if 0
+	MaxRetries    int               // Attempts to send a batch before it is spilled; DefaultMaxRetries if 0
+	Timeout       time.Duration     // Time limit of each request; DefaultTimeout if 0
+	SpillDir      string            // Directory of the spill queue; batches which cannot be sent are dropped if empty
+	MaxSpillBytes int64             // Size of the spill queue above which batches are dropped; unlimited if 0
+	Uncompressed  bool              // Whether requests are sent without gzip compression
+	OnError       func(err error)   // Called when a batch is spilled or dropped; ignored if nil
+}
+
+// entry is one record waiting to be sent
+type entry struct {
+	at   time.Time
+	line []byte
+}
+
+// Shipper is an io.Writer which ships the records written to it to an HTTP endpoint
+type Shipper struct {
+	options Options
+	action  []byte
+
+	lock    sync.Mutex
+	pending []entry
+	size    int
+	closed  bool
+
+	batches chan []entry
+	flushes chan chan error
+	done    chan struct{}
+	stopped chan struct{}
+	err     error
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	spillLock sync.Mutex
+	spillSeq  uint64
+	spilled   int64
+
+	backoff     time.Duration
+	retryAt     time.Time
+	interrupted [][]entry
+}
+
+// permanentError is a failure to send a batch which retrying will not fix
+type permanentError struct {
+	error
+}
+
+// Unwrap returns the cause of the failure
+func (p permanentError) Unwrap() error {
+	return p.error
+}
+
+// New returns a Shipper which sends batches of records as set by its Options
+func New(o Options) (*Shipper, error) {
+	if o.URL == "" {
+		return nil, fmt.Errorf("a shipper must have a URL")
+	}
+	s := &Shipper{
+		batches: make(chan []entry, queueDepth),
+		flushes: make(chan chan error),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	switch o.Encoding {
+	case NDJSON:
+	case Loki:
+		if len(o.Labels) == 0 {
+			return nil, fmt.Errorf("a Loki stream must have at least one label")
+		}
+	case Elasticsearch:
+		if o.Index == "" {
+			return nil, fmt.Errorf("an Elasticsearch shipper must have an index")
+		}
+		index, _ := json.Marshal(o.Index)
+		s.action = fmt.Appendf(nil, `{"create":{"_index":%s}}`+"\n", index)
+	default:
+		return nil, fmt.Errorf("unknown Encoding value %d", o.Encoding)
+	}
+	if o.Client == nil {
+		o.Client = http.DefaultClient
+	}
+	if o.MaxRecords <= 0 {
+		o.MaxRecords = DefaultMaxRecords
+	}
+	if o.MaxBytes <= 0 {
+		o.MaxBytes = DefaultMaxBytes
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = DefaultFlushInterval
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = DefaultMinBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = DefaultMaxBackoff
+	}
+	o.MaxBackoff = max(o.MaxBackoff, o.MinBackoff)
+	if o.MaxRetries <= 0 {
+		o.MaxRetries = DefaultMaxRetries
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+	s.options = o
+	if o.SpillDir != "" {
+		if err := os.MkdirAll(o.SpillDir, 0o750); err != nil {
+			return nil, fmt.Errorf("cannot create the spill queue: %w", err)
+		}
+		files, err := s.queued()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			if info, err := os.Stat(f); err == nil {
+				s.spilled += info.Size()
+			}
+		}
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	go s.run()
+	return s, nil
+}
+
+// Write adds one record to the current batch. A full batch is passed to be
+// sent, or spilled if sending has fallen behind
+func (s *Shipper) Write(p []byte) (int, error) {
+	e := entry{
+		at:   time.Now(),
+		line: bytes.Clone(bytes.TrimSuffix(p, []byte("\n"))),
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return 0, ErrClosed
+	}
+	s.pending = append(s.pending, e)
+	s.size += len(e.line)
+	if len(s.pending) < s.options.MaxRecords && s.size < s.options.MaxBytes {
+		return len(p), nil
+	}
+	// The batch is handed over while the lock is held, so that Close cannot
+	// stop the Shipper before it has been queued or spilled
+	full := s.cutLocked()
+	select {
+	case s.batches <- full:
+	default:
+		if err := s.spill(full, errors.New("sending has fallen behind")); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush sends the records written so far, and any batches in the spill queue.
+// It returns an error if any of them could not be sent. Once one batch has
+// failed, the rest are spilled or dropped without being sent
+func (s *Shipper) Flush() error {
+	reply := make(chan error)
+	select {
+	case s.flushes <- reply:
+		return <-reply
+	case <-s.stopped:
+		return ErrClosed
+	}
+}
+
+// Close flushes the Shipper and stops it. A request in progress is cancelled
+// and made again, and records which cannot be sent are added to the spill
+// queue without being retried
+func (s *Shipper) Close() error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.lock.Unlock()
+	s.cancel()
+	close(s.done)
+	<-s.stopped
+	return s.err
+}
+
+// run sends batches until the Shipper is closed
+func (s *Shipper) run() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(s.options.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case b := <-s.batches:
+			_ = s.ship(s.ctx, b)
+		case <-ticker.C:
+			_ = s.ship(s.ctx, s.cut())
+			_ = s.replay(s.ctx, false)
+		case reply := <-s.flushes:
+			reply <- s.drain(s.ctx, false)
+		case <-s.done:
+			// The final requests, including any which Close interrupted, are
+			// not cancelled, so that Close delivers what it can, but each is
+			// limited by Options.Timeout
+			s.err = s.drain(context.Background(), s.backoff > 0)
+			return
+		}
+	}
+}
+
+// drain sends the spill queue, every waiting batch and the current batch. Once
+// one has failed, or from the start if the endpoint is failing, the rest are
+// held without being sent, so that an endpoint which is not responding delays
+// the caller by one batch at most
+func (s *Shipper) drain(ctx context.Context, failing bool) error {
+	var errs []error
+	if !failing {
+		if err := s.replay(ctx, true); err != nil {
+			errs = append(errs, err)
+			failing = ctx.Err() == nil
+		}
+	}
+	batches := s.interrupted
+	s.interrupted = nil
+	for len(s.batches) > 0 {
+		batches = append(batches, <-s.batches)
+	}
+	batches = append(batches, s.cut())
+	for _, b := range batches {
+		switch {
+		case len(b) == 0:
+		case failing && ctx.Err() == nil:
+			err := errors.New("the endpoint is failing")
+			errs = append(errs, err, s.spill(b, err))
+		default:
+			if err := s.ship(ctx, b); err != nil {
+				errs = append(errs, err)
+				failing = ctx.Err() == nil
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// cut removes and returns the current batch
+func (s *Shipper) cut() []entry {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.cutLocked()
+}
+
+// cutLocked removes and returns the current batch while the lock is held
+func (s *Shipper) cutLocked() []entry {
+	b := s.pending
+	s.pending = nil
+	s.size = 0
+	return b
+}
+
+// ship sends one batch, retrying with backoff, and spills the records which
+// cannot be sent. While the endpoint is failing, batches are spilled without
+// being sent. A batch which Close interrupts is kept for the final drain
+func (s *Shipper) ship(ctx context.Context, b []entry) error {
+	if len(b) == 0 {
+		return nil
+	}
+	if ctx.Err() != nil {
+		s.interrupted = append(s.interrupted, b)
+		return ErrClosed
+	}
+	if s.options.SpillDir != "" && s.backoff > 0 {
+		err := errors.New("the endpoint is failing")
+		return errors.Join(err, s.spill(b, err))
+	}
+	rest, err := s.send(ctx, b)
+	var p permanentError
+	switch {
+	case err == nil:
+		s.recovered()
+		return nil
+	case ctx.Err() != nil:
+		// The endpoint has not failed, Close cancelled the request
+		s.interrupted = append(s.interrupted, rest)
+		return ErrClosed
+	case errors.As(err, &p):
+		s.report(fmt.Errorf("dropped %d records: %w", len(rest), err))
+		return err
+	}
+	s.retryLater()
+	return errors.Join(err, s.spill(rest, err))
+}
+
+// send posts a batch, retrying the records which were not accepted with
+// exponential backoff until they are, a request fails permanently, the
+// attempts run out or the Shipper is closed. It returns the records which
+// were not sent
+func (s *Shipper) send(ctx context.Context, b []entry) (_ []entry, err error) {
+	backoff := s.options.MinBackoff
+	for attempt := 0; attempt < s.options.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-s.done:
+				return b, err
+			}
+			backoff = min(backoff*2, s.options.MaxBackoff)
+		}
+		b, err = s.post(ctx, b)
+		var p permanentError
+		if err == nil || errors.As(err, &p) {
+			return b, err
+		}
+	}
+	return b, err
+}
+
+// post makes one request to send a batch, which is abandoned after
+// Options.Timeout. It returns the records which were not accepted
+func (s *Shipper) post(ctx context.Context, b []entry) ([]entry, error) {
+	body, err := s.encode(b)
+	if err != nil {
+		return b, permanentError{err}
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.options.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.options.URL, bytes.NewReader(body))
+	if err != nil {
+		return b, permanentError{err}
+	}
+	for k, v := range s.options.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.options.Encoding == Loki {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if !s.options.Uncompressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	resp, err := s.options.Client.Do(req)
+	if err != nil {
+		return b, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		_ = resp.Body.Close()
+	}()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		if s.options.Encoding == Elasticsearch {
+			return s.accepted(resp.Body, b)
+		}
+		return nil, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return b, fmt.Errorf("%s responded %s", s.options.URL, resp.Status)
+	}
+	return b, permanentError{fmt.Errorf("%s responded %s", s.options.URL, resp.Status)}
+}
+
+// bulkResponse is the part of a response of the Elasticsearch bulk API which
+// reports the outcome for each record
+type bulkResponse struct {
+	Errors bool                  `json:"errors"`
+	Items  []map[string]bulkItem `json:"items"`
+}
+
+// bulkItem is the outcome of the action for one record
+type bulkItem struct {
+	Status int             `json:"status"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// accepted reads a response of the Elasticsearch bulk API, which can reject
+// some records of a batch while accepting the others. It returns the records
+// which were rejected with a status that retrying may fix, and drops the rest
+func (s *Shipper) accepted(r io.Reader, b []entry) ([]entry, error) {
+	var resp bulkResponse
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		return b, permanentError{fmt.Errorf("cannot read the bulk response of %s: %w", s.options.URL, err)}
+	}
+	if !resp.Errors {
+		return nil, nil
+	}
+	if len(resp.Items) != len(b) {
+		return b, permanentError{fmt.Errorf("%s reported errors for %d items of %d records", s.options.URL, len(resp.Items), len(b))}
+	}
+	var (
+		retry, rejected []entry
+		cause           json.RawMessage
+	)
+	for i, item := range resp.Items {
+		for _, result := range item {
+			switch {
+			case result.Status == http.StatusTooManyRequests || result.Status >= 500:
+				retry = append(retry, b[i])
+			case result.Status >= 300:
+				rejected = append(rejected, b[i])
+				if cause == nil {
+					cause = result.Error
+				}
+			}
+		}
+	}
+	err := fmt.Errorf("%s rejected %d records: %s", s.options.URL, len(rejected), cause)
+	switch {
+	case len(retry) > 0 && len(rejected) > 0:
+		s.report(fmt.Errorf("dropped %d records: %w", len(rejected), err))
+	case len(rejected) > 0:
+		return rejected, permanentError{err}
+	case len(retry) == 0:
+		return nil, nil
+	}
+	return retry, fmt.Errorf("%s could not accept %d records", s.options.URL, len(retry))
+}
+
+// retryLater doubles the delay before the spill queue is next sent
+func (s *Shipper) retryLater() {
+	s.backoff = min(max(s.backoff*2, s.options.MinBackoff), s.options.MaxBackoff)
+	s.retryAt = time.Now().Add(s.backoff)
+}
+
+// recovered resets the backoff once the endpoint accepts a request
+func (s *Shipper) recovered() {
+	s.backoff = 0
+	s.retryAt = time.Time{}
+}
+
+// replay sends the batches in the spill queue, oldest first, stopping at the
+// first which fails. The records of a batch which were not accepted remain in
+// the queue. Unless forced, it waits until the backoff has elapsed
+func (s *Shipper) replay(ctx context.Context, force bool) error {
+	if s.options.SpillDir == "" || (!force && time.Now().Before(s.retryAt)) {
+		return nil
+	}
+	files, err := s.queued()
+	if err != nil {
+		s.report(err)
+		return err
+	}
+	for _, f := range files {
+		b, err := readSpill(f)
+		if err != nil {
+			s.report(err)
+			return err
+		}
+		rest, err := s.post(ctx, b)
+		var p permanentError
+		switch {
+		case err == nil:
+		case errors.As(err, &p):
+			s.report(fmt.Errorf("dropped %d records of spilled batch %s: %w", len(rest), filepath.Base(f), err))
+		default:
+			if len(rest) < len(b) {
+				s.respill(f, rest)
+			}
+			if ctx.Err() == nil {
+				s.retryLater()
+			}
+			return err
+		}
+		s.unspill(f)
+	}
+	s.recovered()
+	return nil
+}
+
+// queued returns the files of the spill queue, oldest first
+func (s *Shipper) queued() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(s.options.SpillDir, "*"+spillSuffix))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the spill queue: %w", err)
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// spill adds a batch which could not be sent because of cause to the spill
+// queue, or drops it if there is no queue or the queue is full
+func (s *Shipper) spill(b []entry, cause error) error {
+	if s.options.SpillDir == "" {
+		err := fmt.Errorf("dropped a batch: %w", cause)
+		s.report(err)
+		return err
+	}
+	data := marshalSpill(b)
+	s.spillLock.Lock()
+	defer s.spillLock.Unlock()
+	if s.options.MaxSpillBytes > 0 && s.spilled+int64(len(data)) > s.options.MaxSpillBytes {
+		err := fmt.Errorf("dropped a batch because the spill queue is full: %w", cause)
+		s.report(err)
+		return err
+	}
+	s.spillSeq++
+	name := filepath.Join(s.options.SpillDir, fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), s.spillSeq%1000000, spillSuffix))
+	if err := writeSpill(name, data); err != nil {
+		err = fmt.Errorf("dropped a batch which could not be spilled: %w", errors.Join(cause, err))
+		s.report(err)
+		return err
+	}
+	s.spilled += int64(len(data))
+	s.report(fmt.Errorf("spilled a batch to %s: %w", filepath.Base(name), cause))
+	return nil
+}
+
+// respill replaces a batch in the spill queue with those of its records which
+// have not been sent
+func (s *Shipper) respill(f string, b []entry) {
+	data := marshalSpill(b)
+	s.spillLock.Lock()
+	defer s.spillLock.Unlock()
+	info, err := os.Stat(f)
+	if err == nil {
+		err = writeSpill(f, data)
+	}
+	if err != nil {
+		s.report(fmt.Errorf("cannot update spilled batch %s: %w", filepath.Base(f), err))
+		return
+	}
+	s.spilled += int64(len(data)) - info.Size()
+}
+
+// unspill removes a batch which has been sent from the spill queue
+func (s *Shipper) unspill(f string) {
+	s.spillLock.Lock()
+	defer s.spillLock.Unlock()
+	info, err := os.Stat(f)
+	if err == nil {
+		err = os.Remove(f)
+	}
+	if err != nil {
+		s.report(err)
+		return
+	}
+	s.spilled -= info.Size()
+}
+
+// writeSpill replaces a file of the spill queue atomically
+func writeSpill(name string, data []byte) error {
+	tmp := strings.TrimSuffix(name, spillSuffix) + ".tmp"
+	err := os.WriteFile(tmp, data, 0o640)
+	if err == nil {
+		err = os.Rename(tmp, name)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
+
+// marshalSpill encodes the records of a batch for the spill queue, one per line
+// as the time in Unix nanoseconds followed by the quoted record
+func marshalSpill(b []entry) []byte {
+	var data []byte
+	for _, e := range b {
+		data = strconv.AppendInt(data, e.at.UnixNano(), 10)
+		data = append(data, ' ')
+		data = strconv.AppendQuote(data, string(e.line))
+		data = append(data, '\n')
+	}
+	return data
+}
+
+// readSpill reads the records of a batch in the spill queue
+func readSpill(f string) ([]entry, error) {
+	data, err := os.ReadFile(f)
+	if err != nil {
+		return nil, err
+	}
+	var b []entry
+	for line := range bytes.Lines(data) {
+		at, quoted, _ := strings.Cut(strings.TrimSuffix(string(line), "\n"), " ")
+		ns, err := strconv.ParseInt(at, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("spilled batch %s is corrupt: %w", filepath.Base(f), err)
+		}
+		record, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("spilled batch %s is corrupt: %w", filepath.Base(f), err)
+		}
+		b = append(b, entry{at: time.Unix(0, ns), line: []byte(record)})
+	}
+	return b, nil
+}
+
+// report passes an error to OnError
+func (s *Shipper) report(err error) {
+	if s.options.OnError != nil {
+		s.options.OnError(err)
+	}
+}
+
+// lokiPush is the body of a request to the Loki push API
+type lokiPush struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+// lokiStream is the records of one Loki stream
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// encode makes the body of the request which sends a batch
+func (s *Shipper) encode(b []entry) ([]byte, error) {
+	var (
+		buf bytes.Buffer
+		w   io.Writer = &buf
+		zw  *gzip.Writer
+	)
+	if !s.options.Uncompressed {
+		zw = gzip.NewWriter(&buf)
+		w = zw
+	}
+	var err error
+	switch s.options.Encoding {
+	case Loki:
+		stream := lokiStream{Stream: s.options.Labels, Values: make([][2]string, len(b))}
+		for i, e := range b {
+			stream.Values[i] = [2]string{strconv.FormatInt(e.at.UnixNano(), 10), string(e.line)}
+		}
+		err = json.NewEncoder(w).Encode(lokiPush{Streams: []lokiStream{stream}})
+	default:
+		for _, e := range b {
+			if s.action != nil {
+				_, _ = w.Write(s.action)
+			}
+			_, _ = w.Write(e.line)
+			_, err = w.Write([]byte("\n"))
+		}
+	}
+	if zw != nil {
+		err = errors.Join(err, zw.Close())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode a batch: %w", err)
+	}
+	return buf.Bytes(), nil
+}
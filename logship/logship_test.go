@@ -0,0 +1,801 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logship
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bruceesmith/logger"
+)
+
+// collector is an HTTP endpoint which records the batches posted to it
+type collector struct {
+	lock     sync.Mutex
+	bodies   []string
+	types    []string
+	requests atomic.Int32
+	failures atomic.Int32
+	status   int
+	received chan struct{}
+}
+
+func newCollector() *collector {
+	return &collector{status: http.StatusServiceUnavailable, received: make(chan struct{}, 100)}
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.requests.Add(1)
+	if c.failures.Load() > 0 {
+		c.failures.Add(-1)
+		w.WriteHeader(c.status)
+		return
+	}
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.lock.Lock()
+	c.bodies = append(c.bodies, string(b))
+	c.types = append(c.types, r.Header.Get("Content-Type"))
+	c.lock.Unlock()
+	// The reply is also that of the Elasticsearch bulk API for a batch which
+	// has been accepted
+	_, _ = io.WriteString(w, `{"errors":false}`)
+	select {
+	case c.received <- struct{}{}:
+	default:
+	}
+}
+
+// got returns the bodies received so far
+func (c *collector) got() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]string{}, c.bodies...)
+}
+
+// wait waits for a batch to be received
+func (c *collector) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-c.received:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no batch received")
+	}
+}
+
+// spilled returns the files of a spill queue
+func spilled(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*"+spillSuffix))
+	if err != nil {
+		t.Fatalf("filepath.Glob() error %v", err)
+	}
+	return files
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		wantErr bool
+	}{
+		{
+			name:    "ndjson",
+			options: Options{URL: "http://localhost"},
+		},
+		{
+			name:    "no-url",
+			options: Options{},
+			wantErr: true,
+		},
+		{
+			name:    "loki-no-labels",
+			options: Options{URL: "http://localhost", Encoding: Loki},
+			wantErr: true,
+		},
+		{
+			name:    "elasticsearch-no-index",
+			options: Options{URL: "http://localhost", Encoding: Elasticsearch},
+			wantErr: true,
+		},
+		{
+			name:    "bad-encoding",
+			options: Options{URL: "http://localhost", Encoding: 99},
+			wantErr: true,
+		},
+		{
+			name:    "bad-spill-dir",
+			options: Options{URL: "http://localhost", SpillDir: "/dev/null/spill"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if s != nil {
+				_ = s.Close()
+			}
+		})
+	}
+}
+
+func TestShipper_encoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		wantType string
+		wantRe   string
+	}{
+		{
+			name:     "ndjson",
+			options:  Options{},
+			wantType: "application/x-ndjson",
+			wantRe:   `^{"msg":"one"}\n{"msg":"two"}\n$`,
+		},
+		{
+			name:     "ndjson-uncompressed",
+			options:  Options{Uncompressed: true},
+			wantType: "application/x-ndjson",
+			wantRe:   `^{"msg":"one"}\n{"msg":"two"}\n$`,
+		},
+		{
+			name:     "loki",
+			options:  Options{Encoding: Loki, Labels: map[string]string{"job": "test"}},
+			wantType: "application/json",
+			wantRe:   `^{"streams":\[{"stream":{"job":"test"},"values":\[\["\d+","{\\"msg\\":\\"one\\"}"\],\["\d+","{\\"msg\\":\\"two\\"}"\]\]}\]}\n$`,
+		},
+		{
+			name:     "elasticsearch",
+			options:  Options{Encoding: Elasticsearch, Index: "logs"},
+			wantType: "application/x-ndjson",
+			wantRe:   `^{"create":{"_index":"logs"}}\n{"msg":"one"}\n{"create":{"_index":"logs"}}\n{"msg":"two"}\n$`,
+		},
+		{
+			name:     "elasticsearch-escaped",
+			options:  Options{Encoding: Elasticsearch, Index: "logs\x01"},
+			wantType: "application/x-ndjson",
+			wantRe:   `^{"create":{"_index":"logs\\u0001"}}\n{"msg":"one"}\n{"create":{"_index":"logs\\u0001"}}\n{"msg":"two"}\n$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCollector()
+			srv := httptest.NewServer(c)
+			defer srv.Close()
+			o := tt.options
+			o.URL = srv.URL
+			o.FlushInterval = time.Hour
+			s, err := New(o)
+			if err != nil {
+				t.Fatalf("New() error %v", err)
+			}
+			defer s.Close()
+			_, _ = s.Write([]byte(`{"msg":"one"}` + "\n"))
+			_, _ = s.Write([]byte(`{"msg":"two"}` + "\n"))
+			if err := s.Flush(); err != nil {
+				t.Fatalf("Shipper.Flush() error %v", err)
+			}
+			got := c.got()
+			if len(got) != 1 {
+				t.Fatalf("Shipper.Flush() got %d batches want 1", len(got))
+			}
+			if ok, err := regexp.MatchString(tt.wantRe, got[0]); !ok {
+				t.Errorf("Shipper.Flush() got %s want %s error %v", got[0], tt.wantRe, err)
+			}
+			if c.types[0] != tt.wantType {
+				t.Errorf("Shipper.Flush() got Content-Type %s want %s", c.types[0], tt.wantType)
+			}
+		})
+	}
+}
+
+func TestShipper_batching(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		records []string
+		want    string
+	}{
+		{
+			name:    "records",
+			options: Options{MaxRecords: 2, FlushInterval: time.Hour},
+			records: []string{"one", "two", "three"},
+			want:    "one\ntwo\n",
+		},
+		{
+			name:    "bytes",
+			options: Options{MaxBytes: 6, FlushInterval: time.Hour},
+			records: []string{"one", "two", "three"},
+			want:    "one\ntwo\n",
+		},
+		{
+			name:    "time",
+			options: Options{FlushInterval: 10 * time.Millisecond},
+			records: []string{"one"},
+			want:    "one\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCollector()
+			srv := httptest.NewServer(c)
+			defer srv.Close()
+			o := tt.options
+			o.URL = srv.URL
+			s, err := New(o)
+			if err != nil {
+				t.Fatalf("New() error %v", err)
+			}
+			defer s.Close()
+			for _, r := range tt.records {
+				_, _ = s.Write([]byte(r + "\n"))
+			}
+			c.wait(t)
+			if got := c.got()[0]; got != tt.want {
+				t.Errorf("Shipper.Write() got %q want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShipper_retry(t *testing.T) {
+	c := newCollector()
+	c.failures.Store(2)
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	s, err := New(Options{URL: srv.URL, FlushInterval: time.Hour, MinBackoff: time.Millisecond, MaxRetries: 3})
+	if err != nil {
+		t.Fatalf("New() error %v", err)
+	}
+	defer s.Close()
+	_, _ = s.Write([]byte("one\n"))
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Shipper.Flush() error %v", err)
+	}
+	if got := c.requests.Load(); got != 3 {
+		t.Errorf("Shipper.Flush() got %d requests want 3", got)
+	}
+	if got := c.got(); len(got) != 1 || got[0] != "one\n" {
+		t.Errorf("Shipper.Flush() got %q want [one]", got)
+	}
+}
+
+func TestShipper_spill(t *testing.T) {
+	c := newCollector()
+	c.failures.Store(1000)
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	dir := t.TempDir()
+	var reported atomic.Int32
+	s, err := New(Options{
+		URL:           srv.URL,
+		FlushInterval: time.Hour,
+		MinBackoff:    time.Millisecond,
+		MaxRetries:    2,
+		SpillDir:      dir,
+		OnError:       func(error) { reported.Add(1) },
+	})
+	if err != nil {
+		t.Fatalf("New() error %v", err)
+	}
+	defer s.Close()
+	_, _ = s.Write([]byte("one\n"))
+	if err := s.Flush(); err == nil {
+		t.Errorf("Shipper.Flush() got no error with the endpoint down")
+	}
+	if got := c.requests.Load(); got != 2 {
+		t.Errorf("Shipper.Flush() got %d requests want 2 attempts", got)
+	}
+	_, _ = s.Write([]byte("two\n"))
+	if err := s.Flush(); err == nil {
+		t.Errorf("Shipper.Flush() got no error with the endpoint down")
+	}
+	if got := len(spilled(t, dir)); got != 2 {
+		t.Fatalf("Shipper.Flush() spilled %d batches want 2", got)
+	}
+	if reported.Load() != 2 {
+		t.Errorf("OnError got %d calls want 2", reported.Load())
+	}
+	c.failures.Store(0)
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Shipper.Flush() error %v", err)
+	}
+	if got := c.got(); strings.Join(got, "") != "one\ntwo\n" {
+		t.Errorf("Shipper.Flush() got %q want [one two]", got)
+	}
+	if got := len(spilled(t, dir)); got != 0 {
+		t.Errorf("Shipper.Flush() left %d spilled batches want 0", got)
+	}
+}
+
+func TestShipper_spillFull(t *testing.T) {
+	c := newCollector()
+	c.failures.Store(1000)
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	dir := t.TempDir()
+	var errs []error
+	s, err := New(Options{
+		URL:           srv.URL,
+		FlushInterval: time.Hour,
+		MaxRetries:    1,
+		SpillDir:      dir,
+		MaxSpillBytes: 1,
+		OnError:       func(err error) { errs = append(errs, err) },
+	})
+	if err != nil {
+		t.Fatalf("New() error %v", err)
+	}
+	defer s.Close()
+	_, _ = s.Write([]byte("one\n"))
+	if err := s.Flush(); err == nil {
+		t.Errorf("Shipper.Flush() got no error with the endpoint down")
+	}
+	if got := len(spilled(t, dir)); got != 0 {
+		t.Errorf("Shipper.Flush() spilled %d batches want 0", got)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "spill queue is full") {
+		t.Errorf("OnError got %v want a full spill queue", errs)
+	}
+}
+
+func TestShipper_restart(t *testing.T) {
+	c := newCollector()
+	c.failures.Store(1000)
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	dir := t.TempDir()
+	o := Options{URL: srv.URL, FlushInterval: time.Hour, MaxRetries: 1, SpillDir: dir}
+	s, err := New(o)
+	if err != nil {
+		t.Fatalf("New() error %v", err)
+	}
+	_, _ = s.Write([]byte("one\n"))
+	if err := s.Close(); err == nil {
+		t.Errorf("Shipper.Close() got no error with the endpoint down")
+	}
+	if _, err := s.Write([]byte("two\n")); !errors.Is(err, ErrClosed) {
+		t.Errorf("Shipper.Write() got %v want %v", err, ErrClosed)
+	}
+	if got := len(spilled(t, dir)); got != 1 {
+		t.Fatalf("Shipper.Close() spilled %d batches want 1", got)
+	}
+	c.failures.Store(0)
+	s, err = New(o)
+	if err != nil {
+		t.Fatalf("New() error %v", err)
+	}
+	defer s.Close()
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Shipper.Flush() error %v", err)
+	}
+	if got := c.got(); len(got) != 1 || got[0] != "one\n" {
+		t.Errorf("Shipper.Flush() got %q want [one]", got)
+	}
+}
+
+func TestShipper_permanent(t *testing.T) {
+	c := newCollector()
+	c.status = http.StatusBadRequest
+	c.failures.Store(1)
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	dir := t.TempDir()
+	var reported atomic.Int32
+	s, err := New(Options{
+		URL:           srv.URL,
+		FlushInterval: time.Hour,
+		SpillDir:      dir,
+		OnError:       func(error) { reported.Add(1) },
+	})
+	if err != nil {
+		t.Fatalf("New() error %v", err)
+	}
+	defer s.Close()
+	_, _ = s.Write([]byte("one\n"))
+	if err := s.Flush(); err == nil {
+		t.Errorf("Shipper.Flush() got no error for a rejected batch")
+	}
+	if got := c.requests.Load(); got != 1 {
+		t.Errorf("Shipper.Flush() got %d requests want 1", got)
+	}
+	if got := len(spilled(t, dir)); got != 0 {
+		t.Errorf("Shipper.Flush() spilled %d batches want 0", got)
+	}
+	if reported.Load() != 1 {
+		t.Errorf("OnError got %d calls want 1", reported.Load())
+	}
+}
+
+func TestShipper_logger(t *testing.T) {
+	defer func() {
+		_ = logger.Configure(
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: os.Stdout},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.FormatSetting, Value: logger.Text},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: false},
+		)
+	}()
+	c := newCollector()
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	s, err := New(Options{URL: srv.URL, Encoding: Loki, Labels: map[string]string{"job": "test"}, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("New() error %v", err)
+	}
+	defer s.Close()
+	_ = logger.Configure(
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: s},
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.FormatSetting, Value: logger.JSON},
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: true},
+	)
+	logger.Info("shipped", slog.Int("one", 1))
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Shipper.Flush() error %v", err)
+	}
+	want := `^{"streams":\[{"stream":{"job":"test"},"values":\[\["\d+","{\\"level\\":\\"INFO\\",\\"msg\\":\\"shipped\\",\\"one\\":1}"\]\]}\]}\n$`
+	got := c.got()
+	if len(got) != 1 {
+		t.Fatalf("Shipper.Flush() got %d batches want 1", len(got))
+	}
+	if ok, err := regexp.MatchString(want, got[0]); !ok {
+		t.Errorf("Shipper.Flush() got %s want %s error %v", got[0], want, err)
+	}
+}
+
+// spilledRecords counts the records in the batches of a spill queue
+func spilledRecords(t *testing.T, dir string) int {
+	t.Helper()
+	n := 0
+	for _, f := range spilled(t, dir) {
+		b, err := readSpill(f)
+		if err != nil {
+			t.Fatalf("readSpill() error %v", err)
+		}
+		n += len(b)
+	}
+	return n
+}
+
+func TestShipper_closeWhileWriting(t *testing.T) {
+	c := newCollector()
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	// The race is between a Write handing over a full batch and Close, so the
+	// Shipper is closed repeatedly while it is being written to
+	for range 20 {
+		dir := t.TempDir()
+		s, err := New(Options{URL: srv.URL, MaxRecords: 1, FlushInterval: time.Hour, MaxRetries: 1, SpillDir: dir})
+		if err != nil {
+			t.Fatalf("New() error %v", err)
+		}
+		before := strings.Count(strings.Join(c.got(), ""), "\n")
+		var (
+			written atomic.Int32
+			wg      sync.WaitGroup
+		)
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 200 {
+					if _, err := s.Write([]byte("record\n")); err != nil {
+						return
+					}
+					written.Add(1)
+				}
+			}()
+		}
+		time.Sleep(time.Millisecond)
+		_ = s.Close()
+		wg.Wait()
+		delivered := strings.Count(strings.Join(c.got(), ""), "\n") - before
+		// A request cancelled by Close may have been received, and so the
+		// records in it can be both delivered and spilled
+		if got := delivered + spilledRecords(t, dir); got < int(written.Load()) {
+			t.Fatalf("Shipper.Close() delivered %d and spilled %d records want %d written", delivered, got-delivered, written.Load())
+		}
+	}
+}
+
+func TestShipper_hang(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		close   bool
+	}{
+		{
+			name:    "flush",
+			timeout: 20 * time.Millisecond,
+		},
+		{
+			name:    "close",
+			timeout: 100 * time.Millisecond,
+			close:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				started = make(chan struct{}, 100)
+				release = make(chan struct{})
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				started <- struct{}{}
+				select {
+				case <-r.Context().Done():
+				case <-release:
+				}
+			}))
+			defer srv.Close()
+			defer close(release)
+			s, err := New(Options{
+				URL:           srv.URL,
+				MaxRecords:    1,
+				FlushInterval: time.Hour,
+				MinBackoff:    time.Millisecond,
+				MaxRetries:    2,
+				Timeout:       tt.timeout,
+			})
+			if err != nil {
+				t.Fatalf("New() error %v", err)
+			}
+			defer s.Close()
+			for range 5 {
+				_, _ = s.Write([]byte("record\n"))
+			}
+			<-started
+			begin := time.Now()
+			if tt.close {
+				err = s.Close()
+			} else {
+				err = s.Flush()
+			}
+			if err == nil {
+				t.Errorf("Shipper.Flush() got no error with the endpoint hanging")
+			}
+			if elapsed := time.Since(begin); elapsed > 2*time.Second {
+				t.Errorf("Shipper.Flush() took %v with the endpoint hanging", elapsed)
+			}
+		})
+	}
+}
+
+// bulkEndpoint is an Elasticsearch bulk API which replies to each request in
+// turn with one of its responses, repeating the last
+type bulkEndpoint struct {
+	lock      sync.Mutex
+	responses []string
+	docs      []string
+}
+
+func (e *bulkEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, _ := io.ReadAll(r.Body)
+	var docs []string
+	for line := range strings.Lines(string(b)) {
+		if !strings.HasPrefix(line, `{"create"`) {
+			docs = append(docs, strings.TrimSuffix(line, "\n"))
+		}
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.docs = append(e.docs, strings.Join(docs, ","))
+	_, _ = io.WriteString(w, e.responses[min(len(e.docs), len(e.responses))-1])
+}
+
+// got returns the records of each request received so far
+func (e *bulkEndpoint) got() []string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return append([]string{}, e.docs...)
+}
+
+const (
+	bulkCreated  = `{"create":{"status":201}}`
+	bulkBusy     = `{"create":{"status":429,"error":{"type":"es_rejected_execution_exception"}}}`
+	bulkRejected = `{"create":{"status":400,"error":{"type":"document_parsing_exception"}}}`
+)
+
+func TestShipper_elasticsearchBulk(t *testing.T) {
+	tests := []struct {
+		name         string
+		responses    []string
+		wantDocs     []string
+		wantErr      bool
+		wantReported int32
+		wantSpilled  int
+	}{
+		{
+			name:      "accepted",
+			responses: []string{`{"errors":false,"items":[` + bulkCreated + `,` + bulkCreated + `]}`},
+			wantDocs:  []string{`{"n":1},{"n":2}`},
+		},
+		{
+			name: "retried",
+			responses: []string{
+				`{"errors":true,"items":[` + bulkCreated + `,` + bulkBusy + `]}`,
+				`{"errors":false,"items":[` + bulkCreated + `]}`,
+			},
+			wantDocs: []string{`{"n":1},{"n":2}`, `{"n":2}`},
+		},
+		{
+			name:         "rejected",
+			responses:    []string{`{"errors":true,"items":[` + bulkCreated + `,` + bulkRejected + `]}`},
+			wantDocs:     []string{`{"n":1},{"n":2}`},
+			wantErr:      true,
+			wantReported: 1,
+		},
+		{
+			name: "rejected-and-retried",
+			responses: []string{
+				`{"errors":true,"items":[` + bulkRejected + `,` + bulkBusy + `]}`,
+				`{"errors":false,"items":[` + bulkCreated + `]}`,
+			},
+			wantDocs:     []string{`{"n":1},{"n":2}`, `{"n":2}`},
+			wantReported: 1,
+		},
+		{
+			name: "spilled",
+			responses: []string{
+				`{"errors":true,"items":[` + bulkCreated + `,` + bulkBusy + `]}`,
+				`{"errors":true,"items":[` + bulkBusy + `]}`,
+			},
+			wantDocs:     []string{`{"n":1},{"n":2}`, `{"n":2}`},
+			wantErr:      true,
+			wantReported: 1,
+			wantSpilled:  1,
+		},
+		{
+			name:         "unreadable",
+			responses:    []string{`ok`},
+			wantDocs:     []string{`{"n":1},{"n":2}`},
+			wantErr:      true,
+			wantReported: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &bulkEndpoint{responses: tt.responses}
+			srv := httptest.NewServer(e)
+			defer srv.Close()
+			dir := t.TempDir()
+			var reported atomic.Int32
+			s, err := New(Options{
+				URL:           srv.URL,
+				Encoding:      Elasticsearch,
+				Index:         "logs",
+				FlushInterval: time.Hour,
+				MinBackoff:    time.Millisecond,
+				MaxRetries:    2,
+				SpillDir:      dir,
+				Uncompressed:  true,
+				OnError:       func(error) { reported.Add(1) },
+			})
+			if err != nil {
+				t.Fatalf("New() error %v", err)
+			}
+			defer s.Close()
+			_, _ = s.Write([]byte(`{"n":1}` + "\n"))
+			_, _ = s.Write([]byte(`{"n":2}` + "\n"))
+			if err := s.Flush(); (err != nil) != tt.wantErr {
+				t.Errorf("Shipper.Flush() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := e.got(); !slices.Equal(got, tt.wantDocs) {
+				t.Errorf("Shipper.Flush() sent %q want %q", got, tt.wantDocs)
+			}
+			if got := reported.Load(); got != tt.wantReported {
+				t.Errorf("OnError got %d calls want %d", got, tt.wantReported)
+			}
+			if got := spilledRecords(t, dir); got != tt.wantSpilled {
+				t.Errorf("Shipper.Flush() spilled %d records want %d", got, tt.wantSpilled)
+			}
+		})
+	}
+}
+
+func TestShipper_replayPartial(t *testing.T) {
+	e := &bulkEndpoint{responses: []string{
+		`{"errors":true,"items":[` + bulkBusy + `,` + bulkBusy + `]}`,
+		`{"errors":true,"items":[` + bulkCreated + `,` + bulkBusy + `]}`,
+		`{"errors":false,"items":[` + bulkCreated + `]}`,
+	}}
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+	dir := t.TempDir()
+	s, err := New(Options{
+		URL:           srv.URL,
+		Encoding:      Elasticsearch,
+		Index:         "logs",
+		FlushInterval: time.Hour,
+		MaxRetries:    1,
+		SpillDir:      dir,
+		Uncompressed:  true,
+	})
+	if err != nil {
+		t.Fatalf("New() error %v", err)
+	}
+	defer s.Close()
+	_, _ = s.Write([]byte(`{"n":1}` + "\n"))
+	_, _ = s.Write([]byte(`{"n":2}` + "\n"))
+	for i, want := range []int{2, 1, 0} {
+		if err := s.Flush(); (err != nil) != (want > 0) {
+			t.Errorf("Shipper.Flush() %d error = %v", i, err)
+		}
+		if got := spilledRecords(t, dir); got != want {
+			t.Errorf("Shipper.Flush() %d left %d spilled records want %d", i, got, want)
+		}
+	}
+	want := []string{`{"n":1},{"n":2}`, `{"n":1},{"n":2}`, `{"n":2}`}
+	if got := e.got(); !slices.Equal(got, want) {
+		t.Errorf("Shipper.Flush() sent %q want %q", got, want)
+	}
+	s.spillLock.Lock()
+	defer s.spillLock.Unlock()
+	if s.spilled != 0 {
+		t.Errorf("Shipper.Flush() got a spill queue of %d bytes want 0", s.spilled)
+	}
+}
+
+func TestShipper_closeSlow(t *testing.T) {
+	c := newCollector()
+	started := make(chan struct{}, 100)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		time.Sleep(50 * time.Millisecond)
+		c.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	var errs []error
+	s, err := New(Options{
+		URL:           srv.URL,
+		MaxRecords:    1,
+		FlushInterval: time.Hour,
+		OnError:       func(err error) { errs = append(errs, err) },
+	})
+	if err != nil {
+		t.Fatalf("New() error %v", err)
+	}
+	for range 5 {
+		_, _ = s.Write([]byte("record\n"))
+	}
+	// Close while a request is in progress
+	<-started
+	if err := s.Close(); err != nil {
+		t.Errorf("Shipper.Close() error %v", err)
+	}
+	if got := strings.Count(strings.Join(c.got(), ""), "record\n"); got < 5 {
+		t.Errorf("Shipper.Close() delivered %d records want 5", got)
+	}
+	if len(errs) != 0 {
+		t.Errorf("OnError got %v want none", errs)
+	}
+}
@@ -0,0 +1,341 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+/*
+Package logsql wraps a [database/sql/driver] Driver so that the statements run
+through it are traced by package [github.com/bruceesmith/logger].
+
+	sql.Register("traced-postgres", logsql.Options{SlowThreshold: time.Second}.Wrap(&pq.Driver{}))
+	db, err := sql.Open("traced-postgres", dsn)
+
+When tracing is enabled for the trace ID "sql", each statement is traced with
+its query, its arguments, the number of rows it affected and its duration.
+Arguments can be redacted, such as with RedactAll. Statements which take longer
+than a threshold are also logged as warnings by the normal logger, whether or
+not they are traced.
+*/
+package logsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/bruceesmith/logger"
+)
+
+// DefaultTraceID is the trace ID of statements unless Options.TraceID is set
+const DefaultTraceID = "sql"
+
+// Options configure a wrapped Driver
+type Options struct {
+	TraceID       string                          // Trace ID of statements; DefaultTraceID if empty
+	SlowThreshold time.Duration                   // Duration above which statements are logged as warnings; never if 0
+	Redact        func(arg driver.NamedValue) any // Value traced for each argument; the argument itself if nil
+}
+
+// RedactAll is an Options.Redact function which hides every argument
+func RedactAll(driver.NamedValue) any {
+	return "REDACTED"
+}
+
+// Wrap returns a Driver which traces the statements of d with the default Options
+func Wrap(d driver.Driver) driver.Driver {
+	return Options{}.Wrap(d)
+}
+
+// Wrap returns a Driver which traces the statements of d
+func (o Options) Wrap(d driver.Driver) driver.Driver {
+	if o.TraceID == "" {
+		o.TraceID = DefaultTraceID
+	}
+	if dc, ok := d.(driver.DriverContext); ok {
+		return &contextDriver{wrappedDriver: wrappedDriver{Driver: d, options: o}, dc: dc}
+	}
+	return &wrappedDriver{Driver: d, options: o}
+}
+
+// WrapConnector returns a Connector, for sql.OpenDB, which traces the
+// statements of the connections made by c
+func (o Options) WrapConnector(c driver.Connector) driver.Connector {
+	if o.TraceID == "" {
+		o.TraceID = DefaultTraceID
+	}
+	return &connector{Connector: c, driver: &wrappedDriver{Driver: c.Driver(), options: o}}
+}
+
+// statement logs the outcome of a statement
+func (o Options) statement(ctx context.Context, op string, query string, args []driver.NamedValue, start time.Time, result driver.Result, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+	d := time.Since(start)
+	if logger.TraceIDEnabled(o.TraceID) {
+		attrs := []any{"query", query}
+		if len(args) > 0 {
+			values := make([]any, len(args))
+			for i, a := range args {
+				if o.Redact != nil {
+					values[i] = o.Redact(a)
+				} else {
+					values[i] = a.Value
+				}
+			}
+			attrs = append(attrs, "args", values)
+		}
+		if result != nil {
+			if rows, rerr := result.RowsAffected(); rerr == nil {
+				attrs = append(attrs, "rows", rows)
+			}
+		}
+		attrs = append(attrs, logger.Dur(d))
+		if err != nil {
+			attrs = append(attrs, logger.Err(err))
+		}
+		logger.TraceIDContext(ctx, o.TraceID, "sql "+op, attrs...)
+	}
+	if o.SlowThreshold > 0 && d >= o.SlowThreshold {
+		logger.WarnContext(ctx, "slow sql "+op, "query", query, logger.Dur(d))
+	}
+}
+
+// wrappedDriver is a Driver whose connections trace their statements
+type wrappedDriver struct {
+	driver.Driver
+	options Options
+}
+
+// Open opens a traced connection
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c, options: d.options}, nil
+}
+
+// contextDriver is a wrappedDriver whose driver implements DriverContext
+type contextDriver struct {
+	wrappedDriver
+	dc driver.DriverContext
+}
+
+// OpenConnector returns a Connector which opens traced connections
+func (d *contextDriver) OpenConnector(name string) (driver.Connector, error) {
+	c, err := d.dc.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+	return &connector{Connector: c, driver: &d.wrappedDriver}, nil
+}
+
+// connector opens traced connections
+type connector struct {
+	driver.Connector
+	driver *wrappedDriver
+}
+
+// Connect opens a traced connection
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	cn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: cn, options: c.driver.options}, nil
+}
+
+// Driver returns the wrapped driver
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// conn is a connection which traces its statements
+type conn struct {
+	driver.Conn
+	options Options
+}
+
+// Prepare prepares a traced statement
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	s, err := c.Conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: s, conn: c, query: query}, nil
+}
+
+// PrepareContext prepares a traced statement
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	pc, ok := c.Conn.(driver.ConnPrepareContext)
+	if !ok {
+		return c.Prepare(query)
+	}
+	s, err := pc.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: s, conn: c, query: query}, nil
+}
+
+// BeginTx starts a transaction. As database/sql does for drivers without
+// ConnBeginTx, options other than the defaults are refused
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bt, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return bt.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, errors.New("sql: driver does not support non-default isolation level")
+	}
+	if opts.ReadOnly {
+		return nil, errors.New("sql: driver does not support read-only transactions")
+	}
+	tx, err := c.Conn.Begin()
+	if err == nil {
+		select {
+		case <-ctx.Done():
+			_ = tx.Rollback()
+			return nil, ctx.Err()
+		default:
+		}
+	}
+	return tx, err
+}
+
+// ExecContext runs and traces a statement, if the driver can do so without
+// preparing it
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := ec.ExecContext(ctx, query, args)
+	c.options.statement(ctx, "exec", query, args, start, result, err)
+	return result, err
+}
+
+// QueryContext runs and traces a query, if the driver can do so without
+// preparing it
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := qc.QueryContext(ctx, query, args)
+	c.options.statement(ctx, "query", query, args, start, nil, err)
+	return rows, err
+}
+
+// Ping checks the connection if the driver can
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession resets the connection if the driver can
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid reports whether the connection can be reused
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue checks an argument if the driver can
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// stmt is a prepared statement which traces its execution
+type stmt struct {
+	driver.Stmt
+	conn  *conn
+	query string
+}
+
+// ExecContext runs and traces the statement
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var (
+		result driver.Result
+		err    error
+	)
+	if ec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = ec.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedToValues(args); err == nil {
+			result, err = s.Stmt.Exec(values)
+		}
+	}
+	s.conn.options.statement(ctx, "exec", s.query, args, start, result, err)
+	return result, err
+}
+
+// QueryContext runs and traces the statement
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if qc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedToValues(args); err == nil {
+			rows, err = s.Stmt.Query(values)
+		}
+	}
+	s.conn.options.statement(ctx, "query", s.query, args, start, nil, err)
+	return rows, err
+}
+
+// CheckNamedValue checks an argument in the same way as database/sql would
+// for the driver's statement: by the statement or its connection if either is a
+// NamedValueChecker, else by the statement's ColumnConverter if it has one
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	if err := s.conn.CheckNamedValue(nv); !errors.Is(err, driver.ErrSkip) {
+		return err
+	}
+	if cc, ok := s.Stmt.(driver.ColumnConverter); ok {
+		value, err := cc.ColumnConverter(nv.Ordinal - 1).ConvertValue(nv.Value)
+		if err != nil {
+			return err
+		}
+		nv.Value = value
+		return nil
+	}
+	return driver.ErrSkip
+}
+
+// namedToValues converts arguments for drivers which do not accept names
+func namedToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		if a.Name != "" {
+			return nil, errors.New("logsql: driver does not support named arguments")
+		}
+		values[i] = a.Value
+	}
+	return values, nil
+}
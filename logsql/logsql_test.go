@@ -0,0 +1,298 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logsql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bruceesmith/logger"
+)
+
+// fakeDriver opens fakeConns, which run statements only by preparing them,
+// or directConns, which can also run them directly
+type fakeDriver struct {
+	direct bool
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	if d.direct {
+		return directConn{}, nil
+	}
+	return fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query: query}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type directConn struct {
+	fakeConn
+}
+
+func (directConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return run(query)
+}
+
+func (directConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if _, err := run(query); err != nil {
+		return nil, err
+	}
+	return &fakeRows{}, nil
+}
+
+// run pretends to run a statement, which fails or is slow if its query says so
+func run(query string) (driver.Result, error) {
+	if strings.Contains(query, "fail") {
+		return nil, errors.New("syntax error")
+	}
+	if strings.Contains(query, "slow") {
+		time.Sleep(20 * time.Millisecond)
+	}
+	return driver.RowsAffected(2), nil
+}
+
+type fakeStmt struct {
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return run(s.query)
+}
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	if _, err := run(s.query); err != nil {
+		return nil, err
+	}
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct {
+	done bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"n"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(7)
+	return nil
+}
+
+func init() {
+	sql.Register("logsql-direct", Wrap(fakeDriver{direct: true}))
+	sql.Register("logsql-prepared", Wrap(fakeDriver{}))
+	sql.Register("logsql-redacted", Options{Redact: RedactAll}.Wrap(fakeDriver{direct: true}))
+	sql.Register("logsql-slow", Options{TraceID: "sql.untraced", SlowThreshold: 10 * time.Millisecond}.Wrap(fakeDriver{}))
+}
+
+func TestWrap(t *testing.T) {
+	defer func() {
+		logger.SetLevel(slog.LevelInfo)
+		_ = logger.Configure(
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: os.Stdout},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: false},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: os.Stderr},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.OmitTimeSetting, Value: false},
+		)
+	}()
+	w := &bytes.Buffer{}
+	_ = logger.Configure(
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: true},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.OmitTimeSetting, Value: true},
+	)
+	logger.SetLevel(logger.LevelTrace)
+	logger.SetTraceIds(DefaultTraceID)
+	tests := []struct {
+		name    string
+		driver  string
+		run     func(db *sql.DB) error
+		wantErr bool
+		wantRe  string
+	}{
+		{
+			name:   "exec",
+			driver: "logsql-direct",
+			run: func(db *sql.DB) error {
+				_, err := db.Exec("UPDATE users SET name = ? WHERE id = ?", "bob", 3)
+				return err
+			},
+			wantRe: `^level=TRACE msg="sql exec" query="UPDATE users SET name = \? WHERE id = \?" args="\[bob 3\]" rows=2 duration=\S+` + "\n$",
+		},
+		{
+			name:   "query",
+			driver: "logsql-direct",
+			run: func(db *sql.DB) error {
+				var n int
+				return db.QueryRow("SELECT n FROM t").Scan(&n)
+			},
+			wantRe: `^level=TRACE msg="sql query" query="SELECT n FROM t" duration=\S+` + "\n$",
+		},
+		{
+			name:   "prepared",
+			driver: "logsql-prepared",
+			run: func(db *sql.DB) error {
+				_, err := db.Exec("DELETE FROM users WHERE id = ?", 3)
+				return err
+			},
+			wantRe: `^level=TRACE msg="sql exec" query="DELETE FROM users WHERE id = \?" args=\[3\] rows=2 duration=\S+` + "\n$",
+		},
+		{
+			name:   "redacted",
+			driver: "logsql-redacted",
+			run: func(db *sql.DB) error {
+				_, err := db.Exec("UPDATE users SET password = ?", "secret")
+				return err
+			},
+			wantRe: `^level=TRACE msg="sql exec" query="UPDATE users SET password = \?" args=\[REDACTED\] rows=2 duration=\S+` + "\n$",
+		},
+		{
+			name:   "failed",
+			driver: "logsql-direct",
+			run: func(db *sql.DB) error {
+				_, err := db.Exec("fail")
+				return err
+			},
+			wantErr: true,
+			wantRe:  `^level=TRACE msg="sql exec" query=fail duration=\S+ error="syntax error"` + "\n$",
+		},
+		{
+			name:   "slow",
+			driver: "logsql-slow",
+			run: func(db *sql.DB) error {
+				_, err := db.Exec("slow")
+				return err
+			},
+			wantRe: `^level=WARN msg="slow sql exec" query=slow duration=\S+` + "\n$",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := sql.Open(tt.driver, "")
+			if err != nil {
+				t.Fatalf("sql.Open() error = %v", err)
+			}
+			defer db.Close()
+			if err := db.Ping(); err != nil {
+				t.Fatalf("Ping() error = %v", err)
+			}
+			w.Reset()
+			if err := tt.run(db); (err != nil) != tt.wantErr {
+				t.Fatalf("%s error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			ok, err := regexp.MatchString(tt.wantRe, w.String())
+			if !ok {
+				t.Errorf("Wrap() got %s want %s error %v", w.String(), tt.wantRe, err)
+			}
+		})
+	}
+}
+
+// fakeConnector connects to a fakeDriver
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return directConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                        { return fakeDriver{direct: true} }
+
+func TestOptions_WrapConnector(t *testing.T) {
+	defer func() {
+		logger.SetLevel(slog.LevelInfo)
+		_ = logger.Configure(
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: os.Stderr},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.OmitTimeSetting, Value: false},
+		)
+	}()
+	w := &bytes.Buffer{}
+	_ = logger.Configure(
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.OmitTimeSetting, Value: true},
+	)
+	logger.SetLevel(logger.LevelTrace)
+	logger.SetTraceIds("sql.connector")
+	db := sql.OpenDB(Options{TraceID: "sql.connector"}.WrapConnector(fakeConnector{}))
+	defer db.Close()
+	if _, err := db.Exec("VACUUM"); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	wantRe := `^level=TRACE msg="sql exec" query=VACUUM rows=2 duration=\S+` + "\n$"
+	ok, err := regexp.MatchString(wantRe, w.String())
+	if !ok {
+		t.Errorf("WrapConnector() got %s want %s error %v", w.String(), wantRe, err)
+	}
+}
+
+func TestConn_BeginTx(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		opts    driver.TxOptions
+		wantErr string
+	}{
+		{
+			name: "default",
+			ctx:  context.Background(),
+		},
+		{
+			name:    "read-only",
+			ctx:     context.Background(),
+			opts:    driver.TxOptions{ReadOnly: true},
+			wantErr: "sql: driver does not support read-only transactions",
+		},
+		{
+			name:    "isolation",
+			ctx:     context.Background(),
+			opts:    driver.TxOptions{Isolation: driver.IsolationLevel(sql.LevelSerializable)},
+			wantErr: "sql: driver does not support non-default isolation level",
+		},
+		{
+			name:    "canceled",
+			ctx:     canceled,
+			wantErr: context.Canceled.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Wrap(fakeDriver{}).Open("")
+			if err != nil {
+				t.Fatalf("Open() error %v", err)
+			}
+			tx, err := c.(driver.ConnBeginTx).BeginTx(tt.ctx, tt.opts)
+			if tt.wantErr == "" {
+				if err != nil || tx == nil {
+					t.Errorf("conn.BeginTx() got %v error %v want a transaction", tx, err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("conn.BeginTx() error %v want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
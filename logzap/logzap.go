@@ -0,0 +1,141 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+/*
+Package logzap provides a [zap] Core which writes to the loggers of package
+[github.com/bruceesmith/logger], so that libraries which log with zap obey its
+levels, destinations and trace IDs.
+
+	zl := zap.New(logzap.NewCore(logger.Norm), zap.AddCaller())
+
+Entries below zap's DebugLevel are written to the trace logger. Those from a
+named zap logger are only written when tracing is enabled for its name.
+
+[zap]: https://pkg.go.dev/go.uber.org/zap
+*/
+package logzap
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/bruceesmith/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+// core is a zapcore.Core which writes to a logger and the trace logger
+type core struct {
+	normal slog.Handler
+	trace  slog.Handler
+}
+
+// NewCore returns a zapcore.Core which writes to the identified logger
+func NewCore(id logger.LogID) zapcore.Core {
+	return &core{
+		normal: logger.Handler(id),
+		trace:  logger.Handler(logger.Tracy),
+	}
+}
+
+// level converts a zap level to a slog level
+func level(l zapcore.Level) slog.Level {
+	switch {
+	case l < zapcore.DebugLevel:
+		return logger.LevelTrace
+	case l == zapcore.DebugLevel:
+		return slog.LevelDebug
+	case l == zapcore.InfoLevel:
+		return slog.LevelInfo
+	case l == zapcore.WarnLevel:
+		return slog.LevelWarn
+	case l == zapcore.ErrorLevel:
+		return slog.LevelError
+	case l < zapcore.FatalLevel:
+		return logger.LevelPanic
+	}
+	return logger.LevelFatal
+}
+
+// handler returns the handler for entries at a level
+func (c *core) handler(l zapcore.Level) slog.Handler {
+	if l < zapcore.DebugLevel {
+		return c.trace
+	}
+	return c.normal
+}
+
+// Enabled reports whether entries at a level are written
+func (c *core) Enabled(l zapcore.Level) bool {
+	return c.handler(l).Enabled(context.Background(), level(l))
+}
+
+// With returns a Core which adds fields to each entry
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	return &core{
+		normal: with(c.normal, fields),
+		trace:  with(c.trace, fields),
+	}
+}
+
+// Check adds the Core to a checked entry if it writes the entry
+func (c *core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	if ent.Level < zapcore.DebugLevel && ent.LoggerName != "" && !logger.TraceIDEnabled(ent.LoggerName) {
+		return ce
+	}
+	return ce.AddCore(ent, c)
+}
+
+// Write writes an entry and its fields
+func (c *core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	r := slog.NewRecord(ent.Time, level(ent.Level), ent.Message, ent.Caller.PC)
+	if ent.LoggerName != "" {
+		r.AddAttrs(logger.Component(ent.LoggerName))
+	}
+	r.AddAttrs(attrs(fields)...)
+	return c.handler(ent.Level).Handle(context.Background(), r)
+}
+
+// Sync does nothing, as the loggers write each record as it is emitted
+func (c *core) Sync() error {
+	return nil
+}
+
+// with adds fields to a handler, where a namespace field starts a group
+func with(h slog.Handler, fields []zapcore.Field) slog.Handler {
+	start := 0
+	for i, f := range fields {
+		if f.Type == zapcore.NamespaceType {
+			h = h.WithAttrs(attrs(fields[start:i])).WithGroup(f.Key)
+			start = i + 1
+		}
+	}
+	return h.WithAttrs(attrs(fields[start:]))
+}
+
+// attrs converts fields to attributes, where a namespace field contains the
+// fields after it
+func attrs(fields []zapcore.Field) []slog.Attr {
+	var out []slog.Attr
+	for i, f := range fields {
+		switch f.Type {
+		case zapcore.SkipType:
+		case zapcore.NamespaceType:
+			return append(out, slog.Attr{Key: f.Key, Value: slog.GroupValue(attrs(fields[i+1:])...)})
+		case zapcore.ErrorType:
+			out = append(out, slog.Any(f.Key, f.Interface))
+		default:
+			enc := zapcore.NewMapObjectEncoder()
+			f.AddTo(enc)
+			for _, k := range slices.Sorted(maps.Keys(enc.Fields)) {
+				out = append(out, slog.Any(k, enc.Fields[k]))
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,116 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logzap
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/bruceesmith/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewCore(t *testing.T) {
+	defer func() {
+		logger.SetLevel(slog.LevelInfo)
+		_ = logger.Configure(
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: os.Stdout},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.FormatSetting, Value: logger.Text},
+			logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: false},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: os.Stderr},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.FormatSetting, Value: logger.Text},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.OmitTimeSetting, Value: false},
+			logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.SourceSetting, Value: logger.Source{JSON: true}},
+		)
+	}()
+	w := &bytes.Buffer{}
+	_ = logger.Configure(
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.FormatSetting, Value: logger.JSON},
+		logger.ConfigSetting{AppliesTo: logger.Norm, Key: logger.OmitTimeSetting, Value: true},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.DestinationSetting, Value: w},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.FormatSetting, Value: logger.JSON},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.OmitTimeSetting, Value: true},
+		logger.ConfigSetting{AppliesTo: logger.Tracy, Key: logger.SourceSetting, Value: false},
+	)
+	logger.SetTraceIds("zapped")
+	zl := zap.New(NewCore(logger.Norm))
+	tests := []struct {
+		name   string
+		level  slog.Level
+		log    func()
+		wantRe string
+	}{
+		{
+			name:  "info",
+			level: slog.LevelInfo,
+			log: func() {
+				zl.Info("started", zap.Int("port", 80), zap.Duration("wait", time.Second), zap.Error(errors.New("none")))
+			},
+			wantRe: `^{"level":"INFO","msg":"started","port":80,"wait":1000000000,"error":"none"}` + "\n$",
+		},
+		{
+			name:   "debug-below-level",
+			level:  slog.LevelInfo,
+			log:    func() { zl.Debug("hidden") },
+			wantRe: "^$",
+		},
+		{
+			name:  "with-namespace",
+			level: slog.LevelDebug,
+			log: func() {
+				zl.Named("db").With(zap.String("table", "users"), zap.Namespace("query")).Debug("select", zap.Int("rows", 2))
+			},
+			wantRe: `^{"level":"DEBUG","msg":"select","table":"users","query":{"component":"db","rows":2}}` + "\n$",
+		},
+		{
+			name:   "trace",
+			level:  logger.LevelTrace,
+			log:    func() { zl.Log(zapcore.DebugLevel-1, "traced") },
+			wantRe: `^{"level":"TRACE","msg":"traced"}` + "\n$",
+		},
+		{
+			name:   "trace-id",
+			level:  logger.LevelTrace,
+			log:    func() { zl.Named("zapped").Log(zapcore.DebugLevel-1, "traced") },
+			wantRe: `^{"level":"TRACE","msg":"traced","component":"zapped"}` + "\n$",
+		},
+		{
+			name:   "trace-id-disabled",
+			level:  logger.LevelTrace,
+			log:    func() { zl.Named("other").Log(zapcore.DebugLevel-1, "traced") },
+			wantRe: "^$",
+		},
+		{
+			name:   "trace-disabled",
+			level:  slog.LevelDebug,
+			log:    func() { zl.Log(zapcore.DebugLevel-1, "traced") },
+			wantRe: "^$",
+		},
+		{
+			name:   "dpanic",
+			level:  slog.LevelInfo,
+			log:    func() { zl.DPanic("odd") },
+			wantRe: `^{"level":"PANIC","msg":"odd"}` + "\n$",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w.Reset()
+			logger.SetLevel(tt.level)
+			tt.log()
+			ok, err := regexp.MatchString(tt.wantRe, w.String())
+			if !ok {
+				t.Errorf("NewCore() got %s want %s error %v", w.String(), tt.wantRe, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Metrics is a snapshot of the counts of records emitted by the loggers
+type Metrics struct {
+	Records  map[string]map[string]uint64 `json:"records"`   // Records emitted, by logger name and then level name
+	TraceIDs map[string]uint64            `json:"trace_ids"` // Records emitted by TraceID and its variants, by trace ID
+	Failed   map[string]uint64            `json:"failed"`    // Records which could not be written to at least one destination, by logger name
+	Dropped  map[string]uint64            `json:"dropped"`   // Records which could not be written to any destination, by logger name
+}
+
+// recordKey identifies the count of records emitted by a logger at a level
+type recordKey struct {
+	logger string
+	level  slog.Level
+}
+
+// counters holds the counts from which Metrics are made
+type counters struct {
+	records  sync.Map // recordKey to *atomic.Uint64
+	traceIDs sync.Map // string to *atomic.Uint64
+	failed   sync.Map // string to *atomic.Uint64
+	dropped  sync.Map // string to *atomic.Uint64
+}
+
+var metrics counters
+
+// count increments the counter for a key, adding the counter if it is new
+func count(m *sync.Map, key any) {
+	c, ok := m.Load(key)
+	if !ok {
+		c, _ = m.LoadOrStore(key, &atomic.Uint64{})
+	}
+	c.(*atomic.Uint64).Add(1)
+}
+
+// countTraceID counts one record emitted for a trace ID
+func countTraceID(id string) {
+	count(&metrics.traceIDs, strings.ToLower(id))
+}
+
+// countWrite counts one record emitted by a logger and the outcome of writing it
+func countWrite(name string, l slog.Level, err error) {
+	count(&metrics.records, recordKey{logger: name, level: l})
+	if err == nil {
+		return
+	}
+	count(&metrics.failed, name)
+	var p partialError
+	if !errors.As(err, &p) {
+		count(&metrics.dropped, name)
+	}
+}
+
+// snapshot copies a map of counters keyed by string
+func snapshot(m *sync.Map) map[string]uint64 {
+	s := map[string]uint64{}
+	m.Range(func(k, v any) bool {
+		s[k.(string)] = v.(*atomic.Uint64).Load()
+		return true
+	})
+	return s
+}
+
+// ReadMetrics returns the counts of records emitted by each logger since the
+// program started
+func ReadMetrics() Metrics {
+	m := Metrics{
+		Records:  map[string]map[string]uint64{},
+		TraceIDs: snapshot(&metrics.traceIDs),
+		Failed:   snapshot(&metrics.failed),
+		Dropped:  snapshot(&metrics.dropped),
+	}
+	metrics.records.Range(func(k, v any) bool {
+		key := k.(recordKey)
+		if m.Records[key.logger] == nil {
+			m.Records[key.logger] = map[string]uint64{}
+		}
+		ll := LogLevel(key.level)
+		m.Records[key.logger][ll.String()] += v.(*atomic.Uint64).Load()
+		return true
+	})
+	return m
+}
+
+// countingHandler is a slog.Handler which counts the records handled by a logger
+type countingHandler struct {
+	slog.Handler
+	id   LogID
+	name string
+}
+
+// counted wraps the handler of a logger so that its records are counted and
+// its write errors are reported
+func counted(h slog.Handler, id LogID, name string) slog.Handler {
+	return &countingHandler{Handler: h, id: id, name: name}
+}
+
+// Handle passes the record to the logger's handler, counts it and reports any
+// error to the ErrorHandler
+func (c *countingHandler) Handle(ctx context.Context, r slog.Record) error {
+	err := c.Handler.Handle(ctx, r)
+	countWrite(c.name, r.Level, err)
+	if err != nil {
+		reportError(c.id, err)
+	}
+	return err
+}
+
+// WithAttrs returns a countingHandler whose handler includes the attributes
+func (c *countingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &countingHandler{Handler: c.Handler.WithAttrs(attrs), id: c.id, name: c.name}
+}
+
+// WithGroup returns a countingHandler whose handler qualifies attributes with the group
+func (c *countingHandler) WithGroup(name string) slog.Handler {
+	return &countingHandler{Handler: c.Handler.WithGroup(name), id: c.id, name: c.name}
+}
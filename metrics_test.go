@@ -0,0 +1,125 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	set "github.com/deckarep/golang-set/v2"
+)
+
+// failingWriter is an io.Writer whose writes always fail
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestReadMetrics(t *testing.T) {
+	save := config
+	saveLevel := level.Level()
+	saveDefault := slog.Default()
+	defer func() {
+		config = save
+		level.Set(saveLevel)
+		slog.SetDefault(saveDefault)
+	}()
+	tests := []struct {
+		name        string
+		destination io.Writer
+		sinks       []Sink
+		log         func()
+		logger      string
+		level       string
+		id          string
+		wantFailed  uint64
+		wantDropped uint64
+	}{
+		{
+			name:        "info",
+			destination: &bytes.Buffer{},
+			log:         func() { Info("counted") },
+			logger:      "Norm",
+			level:       "INFO",
+		},
+		{
+			name:        "error",
+			destination: &bytes.Buffer{},
+			log:         func() { Error("counted") },
+			logger:      "Norm",
+			level:       "ERROR",
+		},
+		{
+			name:        "failed",
+			destination: failingWriter{},
+			sinks:       []Sink{{Destination: &bytes.Buffer{}, Format: JSON}},
+			log:         func() { Warn("counted") },
+			logger:      "Norm",
+			level:       "WARN",
+			wantFailed:  1,
+		},
+		{
+			name:        "dropped",
+			destination: failingWriter{},
+			log:         func() { Warn("counted") },
+			logger:      "Norm",
+			level:       "WARN",
+			wantFailed:  1,
+			wantDropped: 1,
+		},
+		{
+			name:        "trace id",
+			destination: &bytes.Buffer{},
+			log:         func() { TraceID("Metrics", "counted") },
+			logger:      "Tracy",
+			level:       "TRACE",
+			id:          "metrics",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetLevel(LevelTrace)
+			config.traceIds = set.NewSet("metrics")
+			lc := loggerConfig{Destination: tt.destination, Sinks: tt.sinks}
+			slog.SetDefault(slog.New(handler(lc, false)))
+			config.traceLogger = slog.New(handler(lc, true))
+			before := ReadMetrics()
+			tt.log()
+			after := ReadMetrics()
+			if got := after.Records[tt.logger][tt.level] - before.Records[tt.logger][tt.level]; got != 1 {
+				t.Errorf("ReadMetrics() records got %d want 1", got)
+			}
+			if got := after.Failed[tt.logger] - before.Failed[tt.logger]; got != tt.wantFailed {
+				t.Errorf("ReadMetrics() failed got %d want %d", got, tt.wantFailed)
+			}
+			if got := after.Dropped[tt.logger] - before.Dropped[tt.logger]; got != tt.wantDropped {
+				t.Errorf("ReadMetrics() dropped got %d want %d", got, tt.wantDropped)
+			}
+			if tt.id != "" {
+				if got := after.TraceIDs[tt.id] - before.TraceIDs[tt.id]; got != 1 {
+					t.Errorf("ReadMetrics() trace ID %s got %d want 1", tt.id, got)
+				}
+			}
+		})
+	}
+}
+
+func TestReadMetrics_audit(t *testing.T) {
+	defer auditDestination(nil)
+	auditDestination(failingWriter{})
+	before := ReadMetrics()
+	AuditRecord("counted")
+	after := ReadMetrics()
+	if got := after.Records["Audit"]["INFO"] - before.Records["Audit"]["INFO"]; got != 1 {
+		t.Errorf("ReadMetrics() audit records got %d want 1", got)
+	}
+	if got := after.Dropped["Audit"] - before.Dropped["Audit"]; got != 1 {
+		t.Errorf("ReadMetrics() audit dropped got %d want 1", got)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Options are the logging settings commonly supplied as command-line flags,
+// environment variables or configuration file entries
+type Options struct {
+	Level       LogLevel // Level of the normal logger
+	Format      Format   // Format of the normal logger; unchanged if empty
+	TraceFormat Format   // Format of the trace logger; unchanged if empty
+	TraceIDs    Traces   // Trace IDs to enable
+	OmitTime    bool     // Whether timestamps are omitted from normal log entries
+	LogFile     string   // Destination of the normal logger; unchanged if empty
+	TraceFile   string   // Destination of the trace logger; unchanged if empty
+}
+
+// Apply pushes the options into the package by calling SetLevel,
+// SetTraceIds and Configure. Level and OmitTime are always applied, while
+// empty formats and destinations leave the current settings unchanged
+func (o Options) Apply() error {
+	settings := []ConfigSetting{
+		{AppliesTo: Norm, Key: OmitTimeSetting, Value: o.OmitTime},
+	}
+	for _, f := range []struct {
+		log    LogID
+		format Format
+	}{
+		{log: Norm, format: o.Format},
+		{log: Tracy, format: o.TraceFormat},
+	} {
+		if f.format == "" {
+			continue
+		}
+		var format Format
+		if err := format.UnmarshalText([]byte(f.format)); err != nil {
+			return err
+		}
+		settings = append(settings, ConfigSetting{AppliesTo: f.log, Key: FormatSetting, Value: format})
+	}
+	for _, d := range []struct {
+		log  LogID
+		name string
+	}{
+		{log: Norm, name: o.LogFile},
+		{log: Tracy, name: o.TraceFile},
+	} {
+		if d.name == "" {
+			continue
+		}
+		w, err := OpenDestination(d.name)
+		if err != nil {
+			return err
+		}
+		settings = append(settings, ConfigSetting{AppliesTo: d.log, Key: DestinationSetting, Value: w})
+	}
+	if err := Configure(settings...); err != nil {
+		return err
+	}
+	SetLevel(slog.Level(o.Level))
+	SetTraceIds(o.TraceIDs...)
+	return nil
+}
+
+// OpenDestination returns the writer for a named log destination, which is
+// either stdout (or -), stderr or the path of a file to be appended to
+func OpenDestination(name string) (io.Writer, error) {
+	switch strings.ToLower(name) {
+	case "stdout", "-":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open log destination %s: %w", name, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,167 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// saveState returns a function which restores the package settings
+// changed by Options.Apply
+func saveState() func() {
+	save := config
+	save.traceIds = config.traceIds.Clone()
+	saveDefault := slog.Default()
+	saveLevel := level.Level()
+	return func() {
+		config = save
+		slog.SetDefault(saveDefault)
+		level.Set(saveLevel)
+	}
+}
+
+func TestOptions_Apply(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		o       Options
+		check   func(t *testing.T)
+		wantErr bool
+	}{
+		{
+			name: "all",
+			o: Options{
+				Level:       LogLevel(slog.LevelDebug),
+				Format:      JSON,
+				TraceFormat: "JSON",
+				TraceIDs:    Traces{"Db", "http"},
+				OmitTime:    true,
+				LogFile:     filepath.Join(dir, "log"),
+				TraceFile:   "stdout",
+			},
+			check: func(t *testing.T) {
+				if level.Level() != slog.LevelDebug {
+					t.Errorf("Options.Apply() level = %v", level.Level())
+				}
+				if config.Normal.Format != JSON || config.Trace.Format != JSON {
+					t.Errorf("Options.Apply() formats = %v, %v", config.Normal.Format, config.Trace.Format)
+				}
+				if !config.traceIds.Contains("db", "http") {
+					t.Errorf("Options.Apply() trace IDs = %v", config.traceIds)
+				}
+				if !config.Normal.OmitTime {
+					t.Errorf("Options.Apply() OmitTime = false")
+				}
+				if f, ok := config.Normal.Destination.(*os.File); !ok || f.Name() != filepath.Join(dir, "log") {
+					t.Errorf("Options.Apply() destination = %v", config.Normal.Destination)
+				}
+				if config.Trace.Destination != os.Stdout {
+					t.Errorf("Options.Apply() trace destination = %v", config.Trace.Destination)
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "unchanged",
+			o: Options{
+				Level: LogLevel(slog.LevelWarn),
+			},
+			check: func(t *testing.T) {
+				if config.Normal.Format != Text || config.Normal.Destination != defaultNormalDestination {
+					t.Errorf("Options.Apply() changed normal logger %v", config.Normal)
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "bad-format",
+			o: Options{
+				Format: "xml",
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad-file",
+			o: Options{
+				TraceFile: filepath.Join(dir, "missing", "trace"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		restore := saveState()
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.o.Apply(); (err != nil) != tt.wantErr {
+				t.Errorf("Options.Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t)
+			}
+		})
+		restore()
+	}
+}
+
+func TestOpenDestination(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		dest    string
+		want    *os.File
+		wantErr bool
+	}{
+		{
+			name: "stdout",
+			dest: "STDOUT",
+			want: os.Stdout,
+		},
+		{
+			name: "dash",
+			dest: "-",
+			want: os.Stdout,
+		},
+		{
+			name: "stderr",
+			dest: "stderr",
+			want: os.Stderr,
+		},
+		{
+			name: "file",
+			dest: filepath.Join(dir, "log"),
+		},
+		{
+			name:    "bad-file",
+			dest:    filepath.Join(dir, "missing", "log"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OpenDestination(tt.dest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OpenDestination() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			f, ok := got.(*os.File)
+			if !ok {
+				t.Fatalf("OpenDestination() = %v, want a file", got)
+			}
+			if tt.want != nil && f != tt.want {
+				t.Errorf("OpenDestination() = %v, want %v", f.Name(), tt.want.Name())
+			}
+			if tt.want == nil {
+				if _, err := f.Write([]byte("x")); err != nil {
+					t.Errorf("OpenDestination() file is not writable: %v", err)
+				}
+				_ = f.Close()
+			}
+		})
+	}
+}
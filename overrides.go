@@ -0,0 +1,185 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// overrideRules are the level overrides set by SetLevelOverrides, with a cache
+// of the override (if any) that applies to each caller PC
+type overrideRules struct {
+	levels map[string]slog.Level // by package path
+	names  map[string]slog.Level // by lower case logger name
+	cache  sync.Map
+}
+
+// override is the cached result of resolving a caller PC
+type override struct {
+	level slog.Level
+	ok    bool
+}
+
+// overriddenKey marks a context whose record has been enabled by an override
+type overriddenKey struct{}
+
+var overrides atomic.Pointer[overrideRules]
+
+// byName returns the override for a logger name, which is not case sensitive
+func (o *overrideRules) byName(name string) (l slog.Level, ok bool) {
+	if name == "" {
+		return
+	}
+	l, ok = o.names[strings.ToLower(name)]
+	return
+}
+
+// byPC returns the override for the package of the function containing pc,
+// or the closest enclosing package path for which there is an override
+func (o *overrideRules) byPC(pc uintptr) (slog.Level, bool) {
+	if cached, ok := o.cache.Load(pc); ok {
+		ov := cached.(override)
+		return ov.level, ov.ok
+	}
+	var ov override
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	for path := packagePath(frame.Function); path != ""; {
+		if ov.level, ov.ok = o.levels[path]; ov.ok {
+			break
+		}
+		i := strings.LastIndex(path, "/")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	o.cache.Store(pc, ov)
+	return ov.level, ov.ok
+}
+
+// packagePath extracts the package path from a fully qualified function name,
+// in which any dots in the last element of the path are escaped as %2e
+func packagePath(function string) string {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot >= 0 {
+		function = function[:slash+1+dot]
+	}
+	return strings.ReplaceAll(function, "%2e", ".")
+}
+
+// overridden reports whether a record has been enabled by a level override
+func overridden(ctx context.Context) bool {
+	return ctx.Value(overriddenKey{}) != nil
+}
+
+// enabled reports whether a level is enabled for a handler, either by a level
+// override for the logger name or the caller's package, or by the handler itself.
+// It returns the context in which to handle a record and the caller's PC, where
+// skip is the number of stack frames between the caller and enabled
+func enabled(ctx context.Context, h slog.Handler, name string, l slog.Level, skip int) (context.Context, uintptr, bool) {
+	rules := overrides.Load()
+	if rules == nil && !h.Enabled(ctx, l) {
+		return ctx, 0, false
+	}
+	pc := callerPC(skip + 1)
+	if rules != nil {
+		var ok bool
+		if ctx, ok = permitted(ctx, rules, h, name, l, pc); !ok {
+			return ctx, 0, false
+		}
+	}
+	return ctx, pc, true
+}
+
+// permitted reports whether level overrides, or failing those the handler
+// itself, enable a record at a level emitted from pc, returning the context
+// in which to handle the record
+func permitted(ctx context.Context, rules *overrideRules, h slog.Handler, name string, l slog.Level, pc uintptr) (context.Context, bool) {
+	min, ok := rules.byName(name)
+	if !ok && pc != 0 {
+		min, ok = rules.byPC(pc)
+	}
+	switch {
+	case ok && l < min:
+		return ctx, false
+	case ok:
+		return context.WithValue(ctx, overriddenKey{}, true), true
+	}
+	return ctx, h.Enabled(ctx, l)
+}
+
+// emit writes a record to a handler if its level is enabled. skip is the
+// number of stack frames between the caller and emit
+func emit(h slog.Handler, name string, l slog.Level, skip int, msg string, args ...any) {
+	emitContext(context.Background(), h, name, l, skip+1, msg, args...)
+}
+
+// emitContext writes a record to a handler if its level is enabled, adding the
+// attributes of a context made by ContextWith
+func emitContext(ctx context.Context, h slog.Handler, name string, l slog.Level, skip int, msg string, args ...any) {
+	ctx, pc, ok := enabled(ctx, h, name, l, skip+1)
+	if !ok {
+		return
+	}
+	r := slog.NewRecord(time.Now(), l, msg, pc)
+	r.AddAttrs(contextAttrs(ctx)...)
+	r.Add(args...)
+	_ = h.Handle(ctx, r)
+}
+
+// emitf writes a record whose message is formatted by fmt.Sprintf to a handler,
+// formatting the message only if its level is enabled
+func emitf(h slog.Handler, name string, l slog.Level, skip int, format string, args ...any) {
+	ctx, pc, ok := enabled(context.Background(), h, name, l, skip+1)
+	if !ok {
+		return
+	}
+	r := slog.NewRecord(time.Now(), l, fmt.Sprintf(format, args...), pc)
+	_ = h.Handle(ctx, r)
+}
+
+// SetLevelOverrides replaces the minimum levels that apply to particular Go
+// packages or named loggers in place of the level set by SetLevel. The spec is a
+// comma-separated list of key=level pairs such as
+//
+//	github.com/acme/db=debug,github.com/acme/http=warn,access=error
+//
+// where each key is either the name of a logger registered by RegisterLogger, in
+// any case, or a package path, which also applies to the packages beneath it. An
+// empty spec removes all overrides
+func SetLevelOverrides(spec string) error {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		overrides.Store(nil)
+		return nil
+	}
+	rules := &overrideRules{
+		levels: make(map[string]slog.Level),
+		names:  make(map[string]slog.Level),
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return fmt.Errorf("invalid level override %q", pair)
+		}
+		var ll LogLevel
+		if err := ll.Set(strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("invalid level override %q: %w", pair, err)
+		}
+		rules.levels[key] = slog.Level(ll)
+		rules.names[strings.ToLower(key)] = slog.Level(ll)
+	}
+	overrides.Store(rules)
+	return nil
+}
@@ -0,0 +1,263 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"runtime"
+	"testing"
+)
+
+func Test_packagePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		function string
+		want     string
+	}{
+		{
+			name:     "function",
+			function: "github.com/acme/db.Open",
+			want:     "github.com/acme/db",
+		},
+		{
+			name:     "method",
+			function: "github.com/acme/db.(*Conn).Query",
+			want:     "github.com/acme/db",
+		},
+		{
+			name:     "dotted",
+			function: "gopkg.in/yaml%2ev3.Unmarshal",
+			want:     "gopkg.in/yaml.v3",
+		},
+		{
+			name:     "main",
+			function: "main.main.func1",
+			want:     "main",
+		},
+		{
+			name:     "no-function",
+			function: "",
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := packagePath(tt.function); got != tt.want {
+				t.Errorf("packagePath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetLevelOverrides(t *testing.T) {
+	defer overrides.Store(nil)
+	tests := []struct {
+		name    string
+		spec    string
+		want    map[string]slog.Level
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			spec: "github.com/acme/db=debug, github.com/acme/http=WARN",
+			want: map[string]slog.Level{
+				"github.com/acme/db":   slog.LevelDebug,
+				"github.com/acme/http": slog.LevelWarn,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "empty",
+			spec:    " ",
+			wantErr: false,
+		},
+		{
+			name:    "no-level",
+			spec:    "github.com/acme/db",
+			wantErr: true,
+		},
+		{
+			name:    "no-key",
+			spec:    "=debug",
+			wantErr: true,
+		},
+		{
+			name:    "bad-level",
+			spec:    "github.com/acme/db=loud",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overrides.Store(nil)
+			err := SetLevelOverrides(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetLevelOverrides() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			rules := overrides.Load()
+			if tt.want == nil {
+				if rules != nil {
+					t.Errorf("SetLevelOverrides() got %v want none", rules.levels)
+				}
+				return
+			}
+			for k, v := range tt.want {
+				if got, ok := rules.levels[k]; !ok || got != v {
+					t.Errorf("SetLevelOverrides() %s got %v want %v", k, got, v)
+				}
+			}
+		})
+	}
+}
+
+func Test_overrideRules_byPC(t *testing.T) {
+	defer overrides.Store(nil)
+	tests := []struct {
+		name   string
+		spec   string
+		want   slog.Level
+		wantOk bool
+	}{
+		{
+			name:   "package",
+			spec:   "github.com/bruceesmith/logger=debug",
+			want:   slog.LevelDebug,
+			wantOk: true,
+		},
+		{
+			name:   "parent",
+			spec:   "github.com/bruceesmith=warn",
+			want:   slog.LevelWarn,
+			wantOk: true,
+		},
+		{
+			name:   "other",
+			spec:   "github.com/acme/db=debug",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetLevelOverrides(tt.spec); err != nil {
+				t.Fatalf("SetLevelOverrides() error = %v", err)
+			}
+			pc, _, _, _ := runtime.Caller(0)
+			rules := overrides.Load()
+			for range 2 {
+				got, ok := rules.byPC(pc)
+				if ok != tt.wantOk || (ok && got != tt.want) {
+					t.Errorf("overrideRules.byPC() = %v, %v want %v, %v", got, ok, tt.want, tt.wantOk)
+				}
+			}
+		})
+	}
+}
+
+func TestSetLevelOverrides_emit(t *testing.T) {
+	defer resetNamed()
+	defer overrides.Store(nil)
+	save := config
+	saveDefault := slog.Default()
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		slog.SetDefault(saveDefault)
+		level.Set(saveLevel)
+	}()
+	var (
+		norm   = &bytes.Buffer{}
+		sink   = &bytes.Buffer{}
+		access = &bytes.Buffer{}
+	)
+	RedirectStandard(norm)
+	err := Configure(ConfigSetting{
+		AppliesTo: Norm,
+		Key:       SinksSetting,
+		Value:     []Sink{{Destination: sink, Format: Text, Level: slog.LevelError}},
+	})
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	id, err := RegisterLogger("Access", Sink{Destination: access, Level: slog.LevelInfo})
+	if err != nil {
+		t.Fatalf("RegisterLogger() error = %v", err)
+	}
+	tests := []struct {
+		name   string
+		spec   string
+		global slog.Level
+		emit   func()
+		w      *bytes.Buffer
+		wantRe string
+	}{
+		{
+			name:   "package-lowered",
+			spec:   "github.com/bruceesmith/logger=debug",
+			global: slog.LevelWarn,
+			emit:   func() { Debug("hello") },
+			w:      norm,
+			wantRe: `^time=.+ level=DEBUG msg=hello\n$`,
+		},
+		{
+			name:   "package-lowered-sink",
+			spec:   "github.com/bruceesmith/logger=debug",
+			global: slog.LevelWarn,
+			emit:   func() { Debug("hello") },
+			w:      sink,
+			wantRe: `^$`,
+		},
+		{
+			name:   "package-raised",
+			spec:   "github.com/bruceesmith/logger=error",
+			global: slog.LevelDebug,
+			emit:   func() { Warn("hello") },
+			w:      norm,
+			wantRe: `^$`,
+		},
+		{
+			name:   "other-package",
+			spec:   "github.com/acme/db=debug",
+			global: slog.LevelWarn,
+			emit:   func() { Info("hello") },
+			w:      norm,
+			wantRe: `^$`,
+		},
+		{
+			name:   "logger-name",
+			spec:   "access=debug,github.com/bruceesmith/logger=error",
+			global: slog.LevelInfo,
+			emit:   func() { LogTo(id, slog.LevelDebug, "hello") },
+			w:      access,
+			wantRe: `^time=.+ level=DEBUG msg=hello\n$`,
+		},
+		{
+			name:   "logger-name-case",
+			spec:   "ACCESS=debug",
+			global: slog.LevelInfo,
+			emit:   func() { LogTo(id, slog.LevelDebug, "hello") },
+			w:      access,
+			wantRe: `^time=.+ level=DEBUG msg=hello\n$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			norm.Reset()
+			sink.Reset()
+			access.Reset()
+			SetLevel(tt.global)
+			if err := SetLevelOverrides(tt.spec); err != nil {
+				t.Fatalf("SetLevelOverrides() error = %v", err)
+			}
+			tt.emit()
+			ok, err := regexp.MatchString(tt.wantRe, tt.w.String())
+			if !ok {
+				t.Errorf("SetLevelOverrides() got %s want %s error %v", tt.w.String(), tt.wantRe, err)
+			}
+		})
+	}
+}
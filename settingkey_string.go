@@ -11,11 +11,16 @@ func _() {
 	_ = x[DestinationSetting-0]
 	_ = x[FormatSetting-1]
 	_ = x[OmitTimeSetting-2]
+	_ = x[SinksSetting-3]
+	_ = x[LevelSetting-4]
+	_ = x[SourceSetting-5]
+	_ = x[StackTraceSetting-6]
+	_ = x[FallbackSetting-7]
 }
 
-const _SettingKey_name = "DestinationSettingFormatSettingOmitTimeSetting"
+const _SettingKey_name = "DestinationSettingFormatSettingOmitTimeSettingSinksSettingLevelSettingSourceSettingStackTraceSettingFallbackSetting"
 
-var _SettingKey_index = [...]uint8{0, 18, 31, 46}
+var _SettingKey_index = [...]uint8{0, 18, 31, 46, 58, 70, 83, 100, 115}
 
 func (i SettingKey) String() string {
 	idx := int(i) - 0
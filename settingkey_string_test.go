@@ -25,6 +25,31 @@ func TestSettingKey_String(t *testing.T) {
 			i:    OmitTimeSetting,
 			want: "OmitTimeSetting",
 		},
+		{
+			name: "sinks",
+			i:    SinksSetting,
+			want: "SinksSetting",
+		},
+		{
+			name: "level",
+			i:    LevelSetting,
+			want: "LevelSetting",
+		},
+		{
+			name: "source",
+			i:    SourceSetting,
+			want: "SourceSetting",
+		},
+		{
+			name: "stacktrace",
+			i:    StackTraceSetting,
+			want: "StackTraceSetting",
+		},
+		{
+			name: "fallback",
+			i:    FallbackSetting,
+			want: "FallbackSetting",
+		},
 		{
 			name: "whatthe",
 			i:    99,
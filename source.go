@@ -0,0 +1,157 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// StackKey is the key of the stack trace attribute added to records at or
+// above the level set by a StackTraceSetting
+const StackKey = "stack"
+
+// maxStackDepth limits the number of frames in a stack trace
+const maxStackDepth = 64
+
+// SourcePaths determines how the file of a source location is shown
+type SourcePaths int
+
+const (
+	FullPaths     SourcePaths = iota // The absolute path of the file
+	ShortPaths                       // The file name without its directory
+	RelativePaths                    // The path of the file relative to the working directory
+)
+
+// Source determines whether records include the location in the source code
+// from which they were emitted, and how the file of that location is shown.
+// By default, JSON trace records include their source and no others do
+type Source struct {
+	JSON  bool        // Whether JSON-formatted records include their source
+	Text  bool        // Whether text-formatted records include their source
+	Paths SourcePaths // How the file of a source location is shown
+}
+
+// workDir is the base of relative source paths
+var workDir, _ = os.Getwd()
+
+// enabled reports whether records in a format include their source
+func (s Source) enabled(f Format) bool {
+	if f == JSON {
+		return s.JSON
+	}
+	return s.Text
+}
+
+// file returns the path of a source file as configured
+func (s Source) file(path string) string {
+	switch s.Paths {
+	case ShortPaths:
+		return filepath.Base(path)
+	case RelativePaths:
+		if rel, err := filepath.Rel(workDir, path); err == nil {
+			return rel
+		}
+	}
+	return path
+}
+
+// sourceConfig returns the source settings of either the normal or the trace logger
+func sourceConfig(trace bool) Source {
+	if trace {
+		return config.Trace.Source
+	}
+	return config.Normal.Source
+}
+
+// sourceAttr shortens the file of a record's source location if so configured
+func sourceAttr(a slog.Attr, s Source) slog.Attr {
+	if a.Key != slog.SourceKey || s.Paths == FullPaths {
+		return a
+	}
+	if src, ok := a.Value.Any().(*slog.Source); ok {
+		short := *src
+		short.File = s.file(src.File)
+		a.Value = slog.AnyValue(&short)
+	}
+	return a
+}
+
+// stackHandler adds a stack trace to records at or above a level
+type stackHandler struct {
+	slog.Handler
+	level  slog.Leveler
+	source Source
+}
+
+// withStack wraps a handler so that it adds stack traces if so configured
+func withStack(h slog.Handler, lc loggerConfig) slog.Handler {
+	if lc.StackTrace == nil {
+		return h
+	}
+	return &stackHandler{
+		Handler: h,
+		level:   lc.StackTrace,
+		source:  lc.Source,
+	}
+}
+
+// Handle adds a stack trace to a record if its level requires one
+func (s *stackHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= s.level.Level() {
+		r = r.Clone()
+		r.AddAttrs(slog.String(StackKey, s.stack(r.PC)))
+	}
+	return s.Handler.Handle(ctx, r)
+}
+
+// stack formats the stack of the goroutine from the frame containing pc
+// outwards, one frame per line
+func (s *stackHandler) stack(pc uintptr) string {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(2, pcs[:]) // skip [Callers, stack]
+	from := 0
+	for i := range n {
+		if pcs[i] == pc {
+			from = i
+			break
+		}
+	}
+	if pcs[from] != pc {
+		pcs[0], n = pc, 1
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs[from:n])
+	for {
+		frame, more := frames.Next()
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(frame.Function)
+		b.WriteByte(' ')
+		b.WriteString(s.source.file(frame.File))
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// WithAttrs returns a stackHandler whose handler has additional attributes
+func (s *stackHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &stackHandler{Handler: s.Handler.WithAttrs(attrs), level: s.level, source: s.source}
+}
+
+// WithGroup returns a stackHandler whose handler has a group
+func (s *stackHandler) WithGroup(name string) slog.Handler {
+	return &stackHandler{Handler: s.Handler.WithGroup(name), level: s.level, source: s.source}
+}
@@ -0,0 +1,190 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"testing"
+)
+
+func TestConfigure_source(t *testing.T) {
+	save := config
+	saveDefault := slog.Default()
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		slog.SetDefault(saveDefault)
+		level.Set(saveLevel)
+	}()
+	SetLevel(slog.LevelInfo)
+	tests := []struct {
+		name    string
+		format  Format
+		value   any
+		wantErr bool
+		wantRe  string
+	}{
+		{
+			name:   "default",
+			format: JSON,
+			value:  Source{},
+			wantRe: `^{"level":"INFO","msg":"source"}\n$`,
+		},
+		{
+			name:   "json-full",
+			format: JSON,
+			value:  true,
+			wantRe: `^{"level":"INFO","source":{"function":"github.com/bruceesmith/logger.TestConfigure_source.func\d+","file":"/.+/source_test.go","line":\d+},"msg":"source"}\n$`,
+		},
+		{
+			name:   "json-short",
+			format: JSON,
+			value:  Source{JSON: true, Paths: ShortPaths},
+			wantRe: `^{"level":"INFO","source":{"function":".+","file":"source_test.go","line":\d+},"msg":"source"}\n$`,
+		},
+		{
+			name:   "json-only",
+			format: Text,
+			value:  Source{JSON: true},
+			wantRe: "^level=INFO msg=source\n$",
+		},
+		{
+			name:   "text-relative",
+			format: Text,
+			value:  Source{Text: true, Paths: RelativePaths},
+			wantRe: `^level=INFO source=source_test.go:\d+ msg=source` + "\n$",
+		},
+		{
+			name:    "bad-value",
+			value:   "yes",
+			wantErr: true,
+		},
+		{
+			name:    "bad-paths",
+			value:   Source{Paths: 7},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := Configure(
+				ConfigSetting{AppliesTo: Norm, Key: DestinationSetting, Value: w},
+				ConfigSetting{AppliesTo: Norm, Key: OmitTimeSetting, Value: true},
+				ConfigSetting{AppliesTo: Norm, Key: FormatSetting, Value: tt.format},
+				ConfigSetting{AppliesTo: Norm, Key: SourceSetting, Value: tt.value},
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Configure() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			Info("source")
+			ok, err := regexp.MatchString(tt.wantRe, w.String())
+			if !ok {
+				t.Errorf("Configure() source got %s want %s error %v", w.String(), tt.wantRe, err)
+			}
+		})
+	}
+}
+
+func TestConfigure_stackTrace(t *testing.T) {
+	save := config
+	saveDefault := slog.Default()
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		slog.SetDefault(saveDefault)
+		level.Set(saveLevel)
+		resetNamed()
+	}()
+	SetLevel(slog.LevelInfo)
+	id, err := RegisterLogger("stacked", Sink{Format: JSON})
+	if err != nil {
+		t.Fatalf("RegisterLogger() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		id      LogID
+		value   any
+		wantErr bool
+		wantRe  []string
+	}{
+		{
+			name:  "error",
+			id:    Norm,
+			value: slog.LevelError,
+			wantRe: []string{
+				`^{"level":"INFO","msg":"info"}$`,
+				`^{"level":"ERROR","msg":"error","stack":"github.com/bruceesmith/logger.TestConfigure_stackTrace.func\d+ /.+/source_test.go:\d+\\n.*testing.tRunner `,
+			},
+		},
+		{
+			name:  "info",
+			id:    Norm,
+			value: LogLevel(slog.LevelInfo),
+			wantRe: []string{
+				`^{"level":"INFO","msg":"info","stack":"github.com/bruceesmith/logger.TestConfigure_stackTrace`,
+				`^{"level":"ERROR","msg":"error","stack":"github.com/bruceesmith/logger.TestConfigure_stackTrace`,
+			},
+		},
+		{
+			name:  "off",
+			id:    Norm,
+			value: nil,
+			wantRe: []string{
+				`^{"level":"INFO","msg":"info"}$`,
+				`^{"level":"ERROR","msg":"error"}$`,
+			},
+		},
+		{
+			name:  "named",
+			id:    id,
+			value: slog.LevelWarn,
+			wantRe: []string{
+				`^{"level":"INFO","msg":"info"}$`,
+				`^{"level":"ERROR","msg":"error","stack":"github.com/bruceesmith/logger.TestConfigure_stackTrace`,
+			},
+		},
+		{
+			name:    "bad-value",
+			id:      Norm,
+			value:   "error",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := Configure(
+				ConfigSetting{AppliesTo: tt.id, Key: DestinationSetting, Value: w},
+				ConfigSetting{AppliesTo: tt.id, Key: OmitTimeSetting, Value: true},
+				ConfigSetting{AppliesTo: tt.id, Key: FormatSetting, Value: JSON},
+				ConfigSetting{AppliesTo: tt.id, Key: StackTraceSetting, Value: tt.value},
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Configure() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			LogTo(tt.id, slog.LevelInfo, "info")
+			LogTo(tt.id, slog.LevelError, "error")
+			lines := bytes.Split(bytes.TrimSuffix(w.Bytes(), []byte("\n")), []byte("\n"))
+			if len(lines) != len(tt.wantRe) {
+				t.Fatalf("Configure() stack trace got %d records want %d: %s", len(lines), len(tt.wantRe), w.String())
+			}
+			for i := range lines {
+				ok, err := regexp.Match(tt.wantRe[i], lines[i])
+				if !ok {
+					t.Errorf("Configure() stack trace got %s want %s error %v", lines[i], tt.wantRe[i], err)
+				}
+			}
+		})
+	}
+}
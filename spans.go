@@ -0,0 +1,76 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// noSpan is returned by TraceFunc when tracing is not enabled
+var noSpan = func() {}
+
+// TraceFunc traces entry to and exit from the calling function if tracing is
+// enabled for the ID. It is intended to be deferred:
+//
+//	func query(ctx context.Context, q string) (err error) {
+//		defer logger.TraceFunc("db", "query", q, &err)()
+//		...
+//	}
+//
+// Entry is traced with the name of the calling function and args. Exit is traced
+// with the duration of the call, the value of any *error in args, and any panic,
+// which is then re-raised. When tracing is not enabled for the ID, TraceFunc does
+// no work and does not allocate itself. As with slog, the caller still allocates
+// to box each argument which is not a constant, and moves an error whose address
+// is passed to the heap
+func TraceFunc(id string, args ...any) func() {
+	if !traceIDEnabled(id) {
+		return noSpan
+	}
+	pc := callerPC(1)
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	var (
+		attrs = []any{slog.String("func", frame.Function)}
+		errp  *error
+	)
+	for _, a := range args {
+		if e, ok := a.(*error); ok {
+			errp = e
+			continue
+		}
+		attrs = append(attrs, a)
+	}
+	countTraceID(id)
+	traceSpan(pc, "enter", attrs...)
+	start := time.Now()
+	return func() {
+		attrs := []any{
+			slog.String("func", frame.Function),
+			Dur(time.Since(start)),
+		}
+		if errp != nil && *errp != nil {
+			attrs = append(attrs, Err(*errp))
+		}
+		p := recover()
+		if p != nil {
+			attrs = append(attrs, slog.Any("panic", p))
+		}
+		countTraceID(id)
+		traceSpan(pc, "exit", attrs...)
+		if p != nil {
+			panic(p)
+		}
+	}
+}
+
+// traceSpan emits one trace record for TraceFunc
+func traceSpan(pc uintptr, msg string, args ...any) {
+	r := slog.NewRecord(time.Now(), LevelTrace, msg, pc)
+	r.Add(args...)
+	_ = config.traceLogger.Handler().Handle(context.Background(), r)
+}
@@ -0,0 +1,150 @@
+// Copyright © 2024 Bruce Smith <bruceesmith@gmail.com>
+// Use of this source code is governed by the MIT
+// License that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+
+	set "github.com/deckarep/golang-set/v2"
+)
+
+// spanned is traced by TraceFunc in tests
+func spanned(fail bool, panics bool) (err error) {
+	defer TraceFunc("span", "one", 1, &err)()
+	if panics {
+		panic("boom")
+	}
+	if fail {
+		err = errors.New("failed")
+	}
+	return
+}
+
+func TestTraceFunc(t *testing.T) {
+	save := config
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		level.Set(saveLevel)
+	}()
+	tests := []struct {
+		name   string
+		ids    set.Set[string]
+		fail   bool
+		panics bool
+		wantRe []string
+	}{
+		{
+			name: "ok",
+			ids:  set.NewSet("span"),
+			wantRe: []string{
+				`^{"time":".+","level":"TRACE","source":{.+},"msg":"enter","func":"github.com/bruceesmith/logger.spanned","one":1}$`,
+				`^{"time":".+","level":"TRACE","source":{.+},"msg":"exit","func":"github.com/bruceesmith/logger.spanned","duration":\d+}$`,
+			},
+		},
+		{
+			name: "error",
+			ids:  set.NewSet("all"),
+			fail: true,
+			wantRe: []string{
+				`"msg":"enter"`,
+				`"msg":"exit","func":"github.com/bruceesmith/logger.spanned","duration":\d+,"error":"failed"}$`,
+			},
+		},
+		{
+			name:   "panic",
+			ids:    set.NewSet("span"),
+			panics: true,
+			wantRe: []string{
+				`"msg":"enter"`,
+				`"msg":"exit","func":"github.com/bruceesmith/logger.spanned","duration":\d+,"panic":"boom"}$`,
+			},
+		},
+		{
+			name:   "disabled",
+			ids:    set.NewSet("other"),
+			wantRe: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			SetLevel(LevelTrace)
+			config.traceIds = tt.ids
+			config.Trace.Format = JSON
+			config.traceLogger = slog.New(handler(loggerConfig{Destination: w, Format: JSON}, true))
+			func() {
+				defer func() {
+					if p := recover(); (p != nil) != tt.panics {
+						t.Errorf("TraceFunc() panic = %v, want panic %v", p, tt.panics)
+					}
+				}()
+				_ = spanned(tt.fail, tt.panics)
+			}()
+			lines := strings.Split(strings.TrimSuffix(w.String(), "\n"), "\n")
+			if len(tt.wantRe) == 0 {
+				if w.Len() != 0 {
+					t.Errorf("TraceFunc() got %s want nothing", w.String())
+				}
+				return
+			}
+			if len(lines) != len(tt.wantRe) {
+				t.Fatalf("TraceFunc() got %d records want %d: %s", len(lines), len(tt.wantRe), w.String())
+			}
+			for i := range lines {
+				ok, err := regexp.MatchString(tt.wantRe[i], lines[i])
+				if !ok {
+					t.Errorf("TraceFunc() got %s want %s error %v", lines[i], tt.wantRe[i], err)
+				}
+			}
+		})
+	}
+}
+
+// query is traced by TraceFunc as shown in its documentation
+func query(q string) (err error) {
+	defer TraceFunc("span", "query", q, &err)()
+	return nil
+}
+
+func TestTraceFunc_allocs(t *testing.T) {
+	save := config
+	saveLevel := level.Level()
+	defer func() {
+		config = save
+		level.Set(saveLevel)
+	}()
+	SetLevel(LevelTrace)
+	config.traceIds = set.NewSet("other")
+	q := strings.Repeat("q", 3)
+	tests := []struct {
+		name string
+		call func()
+		want float64
+	}{
+		{
+			name: "constant",
+			call: func() { TraceFunc("span", "key", "value")() },
+			want: 0,
+		},
+		{
+			name: "documented",
+			call: func() { _ = query(q) },
+			want: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if allocs := testing.AllocsPerRun(100, tt.call); allocs != tt.want {
+				t.Errorf("TraceFunc() disabled allocs = %v, want %v", allocs, tt.want)
+			}
+		})
+	}
+}
@@ -5,7 +5,9 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
+	"log/slog"
 	"strings"
 )
 
@@ -31,3 +33,63 @@ func (t *Traces) Set(ts string) (err error) {
 func (t *Traces) Type() string {
 	return "Traces"
 }
+
+// LogValue implements slog.LogValuer
+func (t Traces) LogValue() slog.Value {
+	return slog.StringValue(strings.Join(t, ","))
+}
+
+// MarshalJSON implements json.Marshaler, encoding Traces as an array
+func (t Traces) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]string(t))
+}
+
+// MarshalText implements encoding.TextMarshaler, encoding Traces as a
+// comma-separated list
+func (t Traces) MarshalText() ([]byte, error) {
+	return []byte(strings.Join(t, ",")), nil
+}
+
+// MarshalYAML implements yaml.Marshaler, encoding Traces as a sequence
+func (t Traces) MarshalYAML() (any, error) {
+	return []string(t), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts either an array of
+// trace IDs or a comma-separated string
+func (t *Traces) UnmarshalJSON(jason []byte) error {
+	var ids []string
+	if err := json.Unmarshal(jason, &ids); err == nil {
+		*t = ids
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(jason, &s); err != nil {
+		return fmt.Errorf("cannot unmarshal %s, %w", string(jason), err)
+	}
+	return t.UnmarshalText([]byte(s))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (t *Traces) UnmarshalText(text []byte) error {
+	*t = nil
+	if len(text) == 0 {
+		return nil
+	}
+	return t.Set(string(text))
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler. It accepts either a sequence
+// of trace IDs or a comma-separated string
+func (t *Traces) UnmarshalYAML(unmarshal func(any) error) error {
+	var ids []string
+	if err := unmarshal(&ids); err == nil {
+		*t = ids
+		return nil
+	}
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return t.UnmarshalText([]byte(s))
+}